@@ -0,0 +1,60 @@
+package main
+
+import (
+	"github.com/faiface/mainthread"
+	"github.com/go-gl/glfw/v3.3/glfw"
+)
+
+// the system clipboard can only be used from the main thread
+
+func readClipboard() string {
+	var s string
+	mainthread.Call(func() {
+		s = glfw.GetClipboardString()
+	})
+	return s
+}
+
+func writeClipboard(s string) {
+	mainthread.Call(func() {
+		glfw.SetClipboardString(s)
+	})
+}
+
+// handleClipboard copies, cuts and pastes bubbles. Copied bubbles also go onto
+// the system clipboard as text, and text from other programs can be pasted
+// as long as it's a formula.
+func handleClipboard(ctl *controls, status *notice, x, y int) {
+	pg := ctl.pg
+	switch {
+	case ctl.JustPressed("copy-selection"):
+		if err := pg.CheckCopy(); err != nil {
+			status.Set(err)
+			return
+		}
+		pg.CopySelection()
+		writeClipboard(pg.ClipboardText())
+	case ctl.JustPressed("cut"):
+		if err := pg.CheckCut(); err != nil {
+			status.Set(err)
+			return
+		}
+		pg.Execute(pg.CutSelection)
+		writeClipboard(pg.ClipboardText())
+	case ctl.JustPressed("paste"):
+		// the bubbles themselves are kept if they're what's on the system
+		// clipboard, so that they keep their shape
+		if text := readClipboard(); text != "" && text != pg.ClipboardText() {
+			if err := pg.SetClipboardText(text); err != nil {
+				status.Set(err)
+				return
+			}
+		}
+		owner := pg.BelongsTo(x, y)
+		if err := pg.CheckPaste(owner); err != nil {
+			status.Set(err)
+			return
+		}
+		pg.Execute(func() { pg.Paste(owner, x, y) })
+	}
+}
@@ -0,0 +1,84 @@
+// Command vll-export plays a saved proof back, the same way the editor does,
+// and writes it out as an animated GIF or as a numbered PNG for every frame.
+// It doesn't need a window, so it can be run anywhere:
+//
+//	vll-export -o identity.gif identity.vll
+//	vll-export -o frames -fps 30 -sidebar=false -theme print identity.vll
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"vll/page"
+)
+
+var (
+	output  = flag.String("o", "", "the GIF to write, or a directory to write PNGs into (default: the proof's name, with .gif)")
+	width   = flag.Int("width", page.DefaultAnimation.Width, "the width of each frame, in pixels")
+	height  = flag.Int("height", page.DefaultAnimation.Height, "the height of each frame, in pixels")
+	fps     = flag.Int("fps", page.DefaultAnimation.FPS, "how many frames there are a second")
+	speed   = flag.Float64("speed", page.DefaultAnimation.Speed, "how many steps are played a second")
+	sidebar = flag.Bool("sidebar", page.DefaultAnimation.Sidebar, "show the statement and the step being taken beside the proof")
+	theme   = flag.String("theme", page.DefaultTheme.Name, "the colors to draw in: "+strings.Join(page.ThemeNames(), ", "))
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: vll-export [flags] proof"+page.FileExtension)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := export(flag.Arg(0)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func export(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+	pg, err := page.LoadPage(nil, path)
+	if err != nil {
+		return err
+	}
+	t, ok := page.ThemeNamed(*theme)
+	if !ok {
+		return fmt.Errorf("there's no theme called %q; try one of %v", *theme, strings.Join(page.ThemeNames(), ", "))
+	}
+	a := page.Animation{Width: *width, Height: *height, FPS: *fps, Speed: *speed, Sidebar: *sidebar, Theme: t}
+
+	out := *output
+	if out == "" {
+		out = strings.TrimSuffix(filepath.Base(path), page.FileExtension) + ".gif"
+	}
+	if !strings.EqualFold(filepath.Ext(out), ".gif") {
+		files, err := a.WritePNGs(pg, out)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("wrote %v frames to %v\n", len(files), out)
+		return nil
+	}
+
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := a.WriteGIF(pg, f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Printf("wrote %v\n", out)
+	return nil
+}
@@ -0,0 +1,86 @@
+// Command vll-grade checks students' proofs of the exercises in an exercise
+// set, by replaying every step of them, and reports how each student did. It
+// doesn't need a window, so it can be run anywhere:
+//
+//	vll-grade week1.json week1-alice.json week1-bob.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"vll/page"
+)
+
+var asJSON = flag.Bool("json", false, "write the report as JSON")
+
+// report is how one student did
+type report struct {
+	Student string        `json:"student"`
+	File    string        `json:"file"`
+	Proved  int           `json:"proved"`
+	Results []page.Result `json:"results"`
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: vll-grade [-json] exercises.json submission.json...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	set, err := page.LoadExerciseSet(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	var reports []report
+	failed := false
+	for _, path := range flag.Args()[1:] {
+		sub, err := page.LoadSubmission(path)
+		if err == nil && sub.Set != set.Title {
+			err = fmt.Errorf("%v is a submission for %q, not %q", path, sub.Set, set.Title)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+		r := report{Student: sub.Student, File: path, Results: set.Grade(sub)}
+		for _, result := range r.Results {
+			if result.Proved {
+				r.Proved++
+			}
+		}
+		reports = append(reports, r)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(reports)
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintf(w, "%v\n", set.Title)
+		for _, r := range reports {
+			fmt.Fprintf(w, "\n%v\t%v of %v proved\n", r.Student, r.Proved, len(r.Results))
+			for _, result := range r.Results {
+				if result.Proved {
+					fmt.Fprintf(w, "  %v\tproved in %v steps\n", result.Exercise, result.Steps)
+				} else {
+					fmt.Fprintf(w, "  %v\tnot proved: %v\n", result.Exercise, result.Problem)
+				}
+			}
+		}
+		w.Flush()
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,164 @@
+// Package eventlog keeps a record of what happened recently, so that a trace
+// of the events leading up to a bug can be looked at or attached to a report.
+package eventlog
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Level is how important an event is
+type Level int
+
+const (
+	Debug Level = iota
+	Info
+	Warn
+	Error
+)
+
+func (l Level) String() string {
+	switch l {
+	case Debug:
+		return "DEBUG"
+	case Info:
+		return "INFO"
+	case Warn:
+		return "WARN"
+	case Error:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// ParseLevel reads a level written the way String writes it, in any case
+func ParseLevel(s string) (Level, error) {
+	for l := Debug; l <= Error; l++ {
+		if strings.EqualFold(s, l.String()) {
+			return l, nil
+		}
+	}
+	return Debug, fmt.Errorf("unknown log level %q", s)
+}
+
+// Field is a named value attached to an event
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// F makes a field
+func F(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Event is something that happened
+type Event struct {
+	Time   time.Time
+	Level  Level
+	Op     string
+	Fields []Field
+}
+
+// Short is the event without its time or level, for showing in small places
+func (e Event) Short() string {
+	var b strings.Builder
+	b.WriteString(e.Op)
+	for _, f := range e.Fields {
+		fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+	}
+	return b.String()
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%s %-5s %s", e.Time.Format("15:04:05.000"), e.Level, e.Short())
+}
+
+// Log keeps the most recent events in a ring buffer, and also writes them out
+// if it's been given somewhere to write them
+type Log struct {
+	mu     sync.Mutex
+	events []Event
+	next   int
+	full   bool
+	out    io.Writer
+	// events less important than this are ignored
+	level Level
+}
+
+// New makes a log which remembers the given number of events
+func New(size int) *Log {
+	return &Log{events: make([]Event, size)}
+}
+
+// SetOutput writes every event that's logged from now on to w, one per line
+func (l *Log) SetOutput(w io.Writer) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out = w
+}
+
+// SetLevel ignores events less important than the given level
+func (l *Log) SetLevel(level Level) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.level = level
+}
+
+// Log records an event
+func (l *Log) Log(level Level, op string, fields ...Field) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if level < l.level || len(l.events) == 0 {
+		return
+	}
+	e := Event{Time: time.Now(), Level: level, Op: op, Fields: fields}
+	l.events[l.next] = e
+	l.next = (l.next + 1) % len(l.events)
+	if l.next == 0 {
+		l.full = true
+	}
+	if l.out != nil {
+		fmt.Fprintln(l.out, e)
+	}
+}
+
+func (l *Log) Debug(op string, fields ...Field) { l.Log(Debug, op, fields...) }
+func (l *Log) Info(op string, fields ...Field)  { l.Log(Info, op, fields...) }
+func (l *Log) Warn(op string, fields ...Field)  { l.Log(Warn, op, fields...) }
+func (l *Log) Error(op string, fields ...Field) { l.Log(Error, op, fields...) }
+
+// Recent returns up to n of the latest events, oldest first. A negative n returns all of them.
+func (l *Log) Recent(n int) []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	count := l.next
+	if l.full {
+		count = len(l.events)
+	}
+	if n < 0 || n > count {
+		n = count
+	}
+	recent := make([]Event, 0, n)
+	for i := l.next - n; i < l.next; i++ {
+		recent = append(recent, l.events[(i+len(l.events))%len(l.events)])
+	}
+	return recent
+}
+
+// WriteTo writes every event that's been remembered, oldest first
+func (l *Log) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for _, e := range l.Recent(-1) {
+		n, err := fmt.Fprintln(w, e)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
@@ -0,0 +1,46 @@
+package eventlog
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func ops(events []Event) []string {
+	var names []string
+	for _, e := range events {
+		names = append(names, e.Op)
+	}
+	return names
+}
+
+func TestRingBuffer(t *testing.T) {
+	l := New(3)
+	assert.Equal(t, len(l.Recent(-1)), 0)
+	l.Info("a")
+	l.Info("b")
+	assert.DeepEqual(t, ops(l.Recent(-1)), []string{"a", "b"})
+	l.Info("c")
+	l.Info("d")
+	assert.DeepEqual(t, ops(l.Recent(-1)), []string{"b", "c", "d"})
+	assert.DeepEqual(t, ops(l.Recent(2)), []string{"c", "d"})
+}
+
+func TestLevels(t *testing.T) {
+	var out bytes.Buffer
+	l := New(10)
+	l.SetOutput(&out)
+	l.SetLevel(Info)
+	l.Debug("hidden")
+	l.Warn("place", F("bubble", "White \"A\""), F("result", "rejected"))
+	assert.DeepEqual(t, ops(l.Recent(-1)), []string{"place"})
+	assert.Assert(t, strings.HasSuffix(out.String(), "WARN  place bubble=White \"A\" result=rejected\n"), out.String())
+
+	level, err := ParseLevel("debug")
+	assert.NilError(t, err)
+	assert.Equal(t, level, Debug)
+	_, err = ParseLevel("loud")
+	assert.ErrorContains(t, err, "unknown log level")
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"vll/page"
+
+	"github.com/faiface/pixel"
+)
+
+var (
+	exercisesFile = flag.String("exercises", "", "work through the exercises in this file, saving the proofs of them as they go")
+	student       = flag.String("student", os.Getenv("USER"), "whose proofs of the exercises these are")
+)
+
+var exerciseColor = pixel.RGB(0.45, 0.3, 0.55)
+
+// openExercises opens a page for each exercise in the file given on the
+// command line, if there is one, picking up the student's proofs so far
+func openExercises(ws *page.Workspace) (*page.ExerciseSession, error) {
+	if *exercisesFile == "" {
+		return nil, nil
+	}
+	if *student == "" {
+		return nil, errors.New("say whose proofs of the exercises these are with -student")
+	}
+	set, err := page.LoadExerciseSet(*exercisesFile)
+	if err != nil {
+		return nil, err
+	}
+	path := submissionPath(*exercisesFile, *student)
+	sub, err := page.LoadSubmission(path)
+	if os.IsNotExist(err) {
+		sub, err = page.NewSubmission(set, *student, path), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return page.OpenExercises(ws, set, sub)
+}
+
+// submissionPath is where a student's proofs of an exercise set are saved:
+// next to the exercise file, with the student's name after its own
+func submissionPath(set, student string) string {
+	ext := filepath.Ext(set)
+	return strings.TrimSuffix(set, ext) + "-" + student + ext
+}
+
+// describeExercise fills in the section about the exercise on a page, if it's
+// one, along with how the proof of it is going
+func (sb *sidebar) describeExercise(session *page.ExerciseSession, pg *page.Page) {
+	ex, ok := session.Exercise(pg)
+	if !ok {
+		return
+	}
+	s := sb.add("Exercise")
+	s.color = exerciseColor
+	s.line("Prove %v", ex.Statement)
+	if ex.From != "" {
+		s.line("from %v", ex.From)
+	}
+	for _, restriction := range ex.Restrictions() {
+		s.line("(%v)", restriction)
+	}
+	if result := session.Result(pg); result.Proved {
+		s.line("Proved in %v steps!", result.Steps)
+	} else {
+		s.line("%v", result.Problem)
+	}
+	s.line("Saved to %v", session.Submission.File)
+}
@@ -4,7 +4,9 @@ go 1.15
 
 require (
 	github.com/davecgh/go-spew v1.1.0
+	github.com/faiface/mainthread v0.0.0-20171120011319-8b78f0a41ae3
 	github.com/faiface/pixel v0.10.0
+	github.com/go-gl/glfw/v3.3/glfw v0.0.0-20191125211704-12ad95a8df72
 	github.com/google/go-cmp v0.5.5 // indirect
 	golang.org/x/image v0.0.0-20210220032944-ac19c3e999fb
 	gotest.tools v2.2.0+incompatible
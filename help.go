@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"vll/keymap"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+)
+
+const helpScale = 2
+
+// drawHelp shows an overlay listing the actions available in the given mode,
+// and what they're bound to
+func drawHelp(win *pixelgl.Window, atlas *text.Atlas, km *keymap.Keymap, mode string) {
+	actions := km.Active(mode)
+
+	lines := make([][2]string, 0, len(actions))
+	keyWidth := 0
+	for _, action := range actions {
+		bindings := make([]string, 0, len(action.Bindings))
+		for _, b := range action.Bindings {
+			bindings = append(bindings, b.String())
+		}
+		keys := strings.Join(bindings, ", ")
+		if keys == "" {
+			keys = "(unbound)"
+		}
+		if len(keys) > keyWidth {
+			keyWidth = len(keys)
+		}
+		lines = append(lines, [2]string{keys, action.Description})
+	}
+
+	txt := text.New(pixel.ZV, atlas)
+	txt.Color = color.White
+	fmt.Fprintf(txt, "%s mode\n\n", mode)
+	for _, line := range lines {
+		fmt.Fprintf(txt, "%-*s  %s\n", keyWidth, line[0], line[1])
+	}
+
+	// center the text in the window, on top of a translucent backdrop
+	bounds := txt.Bounds()
+	size := bounds.Size().Scaled(helpScale)
+	at := win.Bounds().Center().Sub(size.Scaled(0.5))
+	m := pixel.IM.Moved(bounds.Min.Scaled(-1)).Scaled(pixel.ZV, helpScale).Moved(at)
+
+	backdrop := imdraw.New(nil)
+	backdrop.Color = pixel.RGBA{R: 0, G: 0, B: 0, A: 0.85}
+	margin := pixel.V(20, 20)
+	backdrop.Push(at.Sub(margin), at.Add(size).Add(margin))
+	backdrop.Rectangle(0)
+	backdrop.Draw(win)
+
+	txt.Draw(win, m)
+}
@@ -0,0 +1,121 @@
+package keymap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// Mod is a set of modifier keys that have to be held for a binding to trigger
+type Mod int
+
+const (
+	Shift Mod = 1 << iota
+	Ctrl
+	Alt
+	Super
+)
+
+var modNames = []struct {
+	mod   Mod
+	name  string
+	left  pixelgl.Button
+	right pixelgl.Button
+}{
+	{Ctrl, "Ctrl", pixelgl.KeyLeftControl, pixelgl.KeyRightControl},
+	{Alt, "Alt", pixelgl.KeyLeftAlt, pixelgl.KeyRightAlt},
+	{Super, "Super", pixelgl.KeyLeftSuper, pixelgl.KeyRightSuper},
+	{Shift, "Shift", pixelgl.KeyLeftShift, pixelgl.KeyRightShift},
+}
+
+// buttons by their lowercased names, as given by pixelgl.Button.String
+var buttonsByName = func() map[string]pixelgl.Button {
+	buttons := make(map[string]pixelgl.Button)
+	for button := pixelgl.MouseButton1; button <= pixelgl.KeyLast; button++ {
+		if name := button.String(); name != "Invalid" {
+			buttons[strings.ToLower(name)] = button
+		}
+	}
+	return buttons
+}()
+
+// Binding is a way to trigger an action: either a button held together with
+// some modifiers (and optionally double clicked), or a typed character.
+type Binding struct {
+	Mods   Mod
+	Double bool
+	Button pixelgl.Button
+	// if set, the binding is triggered by typing this instead of by a button
+	Typed string
+}
+
+// ParseBinding reads a binding written like "Tab", "Ctrl+C",
+// "Double+MouseButtonLeft" or "\"!\"" (for typed characters).
+func ParseBinding(s string) (Binding, error) {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, `"`) {
+		typed, err := strconv.Unquote(s)
+		if err != nil || typed == "" {
+			return Binding{}, fmt.Errorf("invalid typed binding %s", s)
+		}
+		return Binding{Typed: typed}, nil
+	}
+
+	var b Binding
+	parts := strings.Split(s, "+")
+	for _, part := range parts[:len(parts)-1] {
+		switch strings.ToLower(strings.TrimSpace(part)) {
+		case "shift":
+			b.Mods |= Shift
+		case "ctrl", "control":
+			b.Mods |= Ctrl
+		case "alt":
+			b.Mods |= Alt
+		case "super":
+			b.Mods |= Super
+		case "double":
+			b.Double = true
+		default:
+			return Binding{}, fmt.Errorf("unknown modifier %q in %q", part, s)
+		}
+	}
+
+	name := strings.TrimSpace(parts[len(parts)-1])
+	button, ok := buttonsByName[strings.ToLower(name)]
+	if !ok {
+		return Binding{}, fmt.Errorf("unknown button %q", name)
+	}
+	if b.Double && button > pixelgl.MouseButtonLast {
+		return Binding{}, fmt.Errorf("only mouse buttons can be double clicked, not %q", name)
+	}
+	b.Button = button
+	return b, nil
+}
+
+// String writes the binding the same way ParseBinding reads it
+func (b Binding) String() string {
+	if b.Typed != "" {
+		return strconv.Quote(b.Typed)
+	}
+	s := ""
+	for _, m := range modNames {
+		if b.Mods&m.mod != 0 {
+			s += m.name + "+"
+		}
+	}
+	if b.Double {
+		s += "Double+"
+	}
+	return s + b.Button.String()
+}
+
+func (b Binding) modsHeld(in Input) bool {
+	for _, m := range modNames {
+		if b.Mods&m.mod != 0 && !in.Pressed(m.left) && !in.Pressed(m.right) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,43 @@
+//go:build go1.18
+// +build go1.18
+
+package keymap
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzLoad(f *testing.F) {
+	for _, seed := range []string{
+		"loop = Tab, L\n# comment\ncopy = Double+MouseButtonLeft",
+		`of-course = "!"`,
+		"delete = Ctrl+Shift+Backspace",
+		"= Tab",
+		"loop Tab",
+	} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, config string) {
+		km := New()
+		km.Register("loop", "", nil, "Tab")
+		km.Register("copy", "", nil)
+		km.Register("of-course", "", nil)
+		km.Register("delete", "", nil)
+		if err := km.Load(strings.NewReader(config)); err != nil {
+			return
+		}
+		// every binding that's loaded can be written out and read back
+		for _, action := range km.Actions() {
+			for _, b := range action.Bindings {
+				again, err := ParseBinding(b.String())
+				if err != nil {
+					t.Fatalf("%v was written as %q, which can't be read back: %v", b, b.String(), err)
+				}
+				if again.String() != b.String() {
+					t.Fatalf("%q reads back as %q", b.String(), again.String())
+				}
+			}
+		}
+	})
+}
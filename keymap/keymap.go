@@ -0,0 +1,230 @@
+// Package keymap lets actions be registered by name, and triggered by whatever
+// buttons, key combinations or typed characters they're bound to.
+package keymap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// how quickly a second click has to follow the first to count as a double click
+const doubleClickTime = 350 * time.Millisecond
+
+// Input is the part of a window a keymap reads from. *pixelgl.Window satisfies it.
+type Input interface {
+	Pressed(button pixelgl.Button) bool
+	JustPressed(button pixelgl.Button) bool
+	JustReleased(button pixelgl.Button) bool
+	Typed() string
+}
+
+// Action is something the user can do, along with everything that triggers it
+type Action struct {
+	Name        string
+	Description string
+	// the modes the action is available in
+	Modes    []string
+	Bindings []Binding
+}
+
+// ActiveIn returns whether the action is available in the given mode
+func (a *Action) ActiveIn(mode string) bool {
+	for _, m := range a.Modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Keymap keeps track of the registered actions and their bindings
+type Keymap struct {
+	actions []*Action
+	byName  map[string]*Action
+
+	lastClick map[pixelgl.Button]time.Time
+	double    map[pixelgl.Button]bool
+}
+
+func New() *Keymap {
+	return &Keymap{
+		byName:    make(map[string]*Action),
+		lastClick: make(map[pixelgl.Button]time.Time),
+		double:    make(map[pixelgl.Button]bool),
+	}
+}
+
+// Register adds a new action with its default bindings. The defaults are
+// written the same way as in a config file, and it panics if they can't be
+// parsed, since that's a programming error.
+func (km *Keymap) Register(name, description string, modes []string, defaults ...string) *Action {
+	if _, ok := km.byName[name]; ok {
+		panic("keymap: action registered twice: " + name)
+	}
+	action := &Action{Name: name, Description: description, Modes: modes}
+	for _, s := range defaults {
+		b, err := ParseBinding(s)
+		if err != nil {
+			panic(err)
+		}
+		action.Bindings = append(action.Bindings, b)
+	}
+	km.actions = append(km.actions, action)
+	km.byName[name] = action
+	return action
+}
+
+// Action looks up an action by its name
+func (km *Keymap) Action(name string) *Action {
+	return km.byName[name]
+}
+
+// Actions returns every action, in the order they were registered
+func (km *Keymap) Actions() []*Action {
+	return km.actions
+}
+
+// Active returns the actions available in the given mode, in the order they were registered
+func (km *Keymap) Active(mode string) []*Action {
+	var active []*Action
+	for _, action := range km.actions {
+		if action.ActiveIn(mode) {
+			active = append(active, action)
+		}
+	}
+	return active
+}
+
+// Bind replaces the bindings of an action
+func (km *Keymap) Bind(name string, bindings ...Binding) error {
+	action, ok := km.byName[name]
+	if !ok {
+		return fmt.Errorf("keymap: no action named %q", name)
+	}
+	action.Bindings = bindings
+	return nil
+}
+
+// Load reads bindings from a config file, which has one action per line:
+//
+//	# comments start with a hash
+//	loop = Tab
+//	copy = Double+MouseButtonLeft, Ctrl+C
+//	of-course = "!"
+//
+// Actions that aren't mentioned keep their current bindings.
+func (km *Keymap) Load(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		parts := strings.SplitN(text, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("keymap: line %d: expected action = bindings", line)
+		}
+		name := strings.TrimSpace(parts[0])
+		var bindings []Binding
+		for _, s := range strings.Split(parts[1], ",") {
+			if strings.TrimSpace(s) == "" {
+				continue
+			}
+			b, err := ParseBinding(s)
+			if err != nil {
+				return fmt.Errorf("keymap: line %d: %v", line, err)
+			}
+			bindings = append(bindings, b)
+		}
+		if err := km.Bind(name, bindings...); err != nil {
+			return fmt.Errorf("keymap: line %d: no action named %q", line, name)
+		}
+	}
+	return scanner.Err()
+}
+
+// LoadFile reads bindings from the config file at path, if there is one
+func (km *Keymap) LoadFile(path string) error {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return km.Load(f)
+}
+
+// DefaultPath is where the config file is looked for
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vll", "keys.conf"), nil
+}
+
+// Update keeps track of clicks, and should be called once per frame after
+// the window is updated
+func (km *Keymap) Update(in Input) {
+	now := time.Now()
+	for button := pixelgl.MouseButton1; button <= pixelgl.MouseButtonLast; button++ {
+		if in.JustPressed(button) {
+			km.double[button] = now.Sub(km.lastClick[button]) < doubleClickTime
+			km.lastClick[button] = now
+		}
+	}
+}
+
+// JustPressed returns whether any binding of the action was just triggered
+func (km *Keymap) JustPressed(in Input, name string) bool {
+	return km.check(name, func(b Binding) bool {
+		if b.Typed != "" {
+			return strings.Contains(in.Typed(), b.Typed)
+		}
+		if b.Double && !km.double[b.Button] {
+			return false
+		}
+		return b.modsHeld(in) && in.JustPressed(b.Button)
+	})
+}
+
+// Pressed returns whether any binding of the action is being held down
+func (km *Keymap) Pressed(in Input, name string) bool {
+	return km.check(name, func(b Binding) bool {
+		if b.Typed != "" {
+			return strings.Contains(in.Typed(), b.Typed)
+		}
+		return b.modsHeld(in) && in.Pressed(b.Button)
+	})
+}
+
+// JustReleased returns whether the button of any binding of the action was just released
+func (km *Keymap) JustReleased(in Input, name string) bool {
+	return km.check(name, func(b Binding) bool {
+		return b.Typed == "" && in.JustReleased(b.Button)
+	})
+}
+
+func (km *Keymap) check(name string, triggered func(Binding) bool) bool {
+	action, ok := km.byName[name]
+	if !ok {
+		panic("keymap: no action named " + name)
+	}
+	for _, b := range action.Bindings {
+		if triggered(b) {
+			return true
+		}
+	}
+	return false
+}
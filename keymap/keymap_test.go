@@ -0,0 +1,101 @@
+package keymap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/faiface/pixel/pixelgl"
+	"gotest.tools/assert"
+)
+
+type fakeInput struct {
+	pressed     map[pixelgl.Button]bool
+	justPressed map[pixelgl.Button]bool
+	typed       string
+}
+
+func newFakeInput() *fakeInput {
+	return &fakeInput{
+		pressed:     make(map[pixelgl.Button]bool),
+		justPressed: make(map[pixelgl.Button]bool),
+	}
+}
+
+func (in *fakeInput) press(buttons ...pixelgl.Button) {
+	in.justPressed = make(map[pixelgl.Button]bool)
+	for _, button := range buttons {
+		in.pressed[button] = true
+		in.justPressed[button] = true
+	}
+}
+
+func (in *fakeInput) Pressed(button pixelgl.Button) bool      { return in.pressed[button] }
+func (in *fakeInput) JustPressed(button pixelgl.Button) bool  { return in.justPressed[button] }
+func (in *fakeInput) JustReleased(button pixelgl.Button) bool { return false }
+func (in *fakeInput) Typed() string                           { return in.typed }
+
+func TestParseBinding(t *testing.T) {
+	for _, s := range []string{"Tab", "Ctrl+C", "Ctrl+Shift+Z", "Double+MouseButtonLeft", `"!"`} {
+		b, err := ParseBinding(s)
+		assert.NilError(t, err)
+		assert.Equal(t, b.String(), s)
+	}
+
+	b, err := ParseBinding(" control + backspace ")
+	assert.NilError(t, err)
+	assert.Equal(t, b, Binding{Mods: Ctrl, Button: pixelgl.KeyBackspace})
+
+	for _, s := range []string{"", "Hyper+A", "NotAKey", "Double+Tab", `""`} {
+		_, err := ParseBinding(s)
+		assert.Assert(t, err != nil, s)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	km := New()
+	km.Register("loop", "Wrap in a loop", []string{"Create"}, "Tab")
+	km.Register("delete", "Delete", []string{"Create"}, "Backspace", "Delete")
+
+	err := km.Load(strings.NewReader(`
+# loops are important enough for two bindings
+loop = L, "o"
+`))
+	assert.NilError(t, err)
+	assert.Equal(t, len(km.Action("loop").Bindings), 2)
+	assert.Equal(t, km.Action("loop").Bindings[1].Typed, "o")
+	assert.Equal(t, len(km.Action("delete").Bindings), 2)
+
+	assert.ErrorContains(t, km.Load(strings.NewReader("nothing = A")), "line 1")
+	assert.ErrorContains(t, km.Load(strings.NewReader("\nloop Tab")), "line 2")
+	assert.ErrorContains(t, km.Load(strings.NewReader("loop = Tob")), "unknown button")
+}
+
+func TestTriggers(t *testing.T) {
+	km := New()
+	km.Register("grab", "Grab", []string{"Create", "Proof"}, "MouseButtonLeft")
+	km.Register("copy", "Copy", []string{"Create"}, "Double+MouseButtonLeft", "Ctrl+C")
+	km.Register("bang", "Blue loop", []string{"Proof"}, `"!"`)
+
+	in := newFakeInput()
+	in.press(pixelgl.MouseButtonLeft)
+	km.Update(in)
+	assert.Assert(t, km.JustPressed(in, "grab"))
+	assert.Assert(t, !km.JustPressed(in, "copy"))
+
+	// second click in quick succession
+	in.press(pixelgl.MouseButtonLeft)
+	km.Update(in)
+	assert.Assert(t, km.JustPressed(in, "copy"))
+
+	in.press(pixelgl.KeyC)
+	assert.Assert(t, !km.JustPressed(in, "copy"))
+	in.press(pixelgl.KeyRightControl, pixelgl.KeyC)
+	assert.Assert(t, km.JustPressed(in, "copy"))
+
+	in.typed = "!"
+	assert.Assert(t, km.JustPressed(in, "bang"))
+
+	assert.Equal(t, len(km.Active("Create")), 2)
+	assert.Equal(t, len(km.Active("Proof")), 2)
+	assert.Equal(t, len(km.Active("Assumption")), 0)
+}
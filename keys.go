@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"vll/keymap"
+	"vll/page"
+
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// the operation each action performs, which decides the modes it's available in
+var operations = map[string]page.Operation{
+	"grab":      page.OpGrab,
+	"select":    page.OpSelect,
+	"lasso":     page.OpSelect,
+	"siblings":  page.OpSelect,
+	"subtree":   page.OpSelect,
+	"copy":      page.OpCopy,
+	"bubble":    page.OpBubble,
+	"assume":    page.OpAssume,
+	"unit":      page.OpUnit,
+	"loop":      page.OpLoop,
+	"of-course": page.OpOfCourse,
+	"why-not":   page.OpWhyNot,
+	"delete":    page.OpDelete,
+	"prove":     page.OpProve,
+	"done":      page.OpDone,
+	"edit":      page.OpEdit,
+
+	"copy-selection": page.OpCopy,
+	"cut":            page.OpCut,
+	"paste":          page.OpPaste,
+}
+
+// replaying is what the help calls the time a proof is being played back
+const replaying = "Replay"
+
+// the actions which control a replay. They're only available while one is
+// being played back, so they can share keys with actions on the page.
+var replayActions = map[string]bool{
+	"play":         true,
+	"step-forward": true,
+	"step-back":    true,
+	"faster":       true,
+	"slower":       true,
+}
+
+// modes lists the names of the modes an action is available in. Actions
+// which aren't operations on the page are also available during a replay.
+func modes(action string) []string {
+	if replayActions[action] {
+		return []string{replaying}
+	}
+	var names []string
+	op, ok := operations[action]
+	for _, m := range page.Modes {
+		if !ok || m.Allows(op) {
+			names = append(names, m.String())
+		}
+	}
+	if !ok {
+		names = append(names, replaying)
+	}
+	return names
+}
+
+// newKeymap registers every action along with its default bindings, and then
+// overrides them with whatever is in the user's config file
+func newKeymap() *keymap.Keymap {
+	km := keymap.New()
+	register := func(name, description string, defaults ...string) {
+		km.Register(name, description, modes(name), defaults...)
+	}
+	register("quit", "Quit", "Escape")
+	register("help", "Show or hide this help", "H")
+	register("log", "Show or hide the latest events", "Ctrl+L")
+	register("grab", "Drag and drop a bubble", "MouseButtonLeft")
+	register("select", "Add or remove a bubble from the selection, or drag a box from the background", "Shift+MouseButtonLeft")
+	register("lasso", "Drag a lasso around bubbles to select them", "Alt+MouseButtonLeft")
+	register("siblings", "Select everything next to the selected bubble", "Ctrl+A")
+	register("subtree", "Select everything inside the selected bubbles", "Ctrl+D")
+	register("copy", "Copy a bubble (only blue loops while proving)", "Double+MouseButtonLeft")
+	register("copy-selection", "Copy the selection, also as a formula for other programs", "Ctrl+C")
+	register("cut", "Cut the selection (only blue loops while proving)", "Ctrl+X")
+	register("paste", "Paste bubbles, or a formula copied from another program", "Ctrl+V")
+	register("pan", "Drag to pan around (scroll to zoom)", "MouseButtonMiddle")
+	register("bubble", "Create a bubble of the opposite color", "MouseButtonRight")
+	register("assume", "Drag to create an assumption, or add a unit to it", "MouseButtonRight")
+	register("unit", "Create a unit of the same color", "Space")
+	register("loop", "Wrap the selection in a loop of the opposite color", "Tab")
+	register("of-course", "Wrap the selection in a blue loop", `"!"`)
+	register("why-not", "Wrap the selection in a red loop", `"?"`)
+	register("delete", "Delete the selection (only redundant loops while proving)", "Backspace", "Delete")
+	register("prove", "Start proving the statement", "Enter")
+	register("done", "Finish editing the contingency", "Enter")
+	register("edit", "Go back to editing the statement (press twice)", "Ctrl+E")
+	register("new-page", "Open a new page", "Ctrl+T")
+	register("close-page", "Close this page (press twice)", "Ctrl+W")
+	register("next-page", "Show the next page", "Ctrl+PageDown")
+	register("previous-page", "Show the previous page", "Ctrl+PageUp")
+	register("save", "Save this page, along with the proof so far", "Ctrl+S")
+	register("replay", "Play back the proof so far, or stop playing it back", "Ctrl+R")
+	register("play", "Play or pause the replay", "Space")
+	register("step-forward", "Skip to the next step of the replay", "Right")
+	register("step-back", "Go back a step in the replay", "Left")
+	register("faster", "Play the replay faster", "Up")
+	register("slower", "Play the replay slower", "Down")
+	register("theme", "Switch to the next color theme", "F2")
+	register("tutorial", "Start the tutorial, or start the lesson over", "F1")
+
+	path, err := keymap.DefaultPath()
+	if err == nil {
+		err = km.LoadFile(path)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "couldn't load key bindings:", err)
+	}
+	return km
+}
+
+// controls only lets actions trigger in the modes that allow them
+type controls struct {
+	km  *keymap.Keymap
+	win *pixelgl.Window
+	pg  *page.Page
+	// whether a proof is being played back
+	replaying bool
+}
+
+func (c *controls) allowed(action string) bool {
+	if replayActions[action] {
+		return c.replaying
+	}
+	op, ok := operations[action]
+	return !ok || c.pg.Mode.Allows(op)
+}
+
+func (c *controls) JustPressed(action string) bool {
+	return c.allowed(action) && c.km.JustPressed(c.win, action)
+}
+
+func (c *controls) JustReleased(action string) bool {
+	return c.allowed(action) && c.km.JustReleased(c.win, action)
+}
+
+// typingVariable returns whether typed letters currently go into a variable
+// name, in which case they shouldn't also trigger actions bound to letter keys
+func typingVariable(pg *page.Page) bool {
+	if len(pg.Highlighted) != 1 || !pg.Mode.Allows(page.OpRename) {
+		return false
+	}
+	switch pg.Mode {
+	case page.CreateMode, page.ContingencyMode:
+		highlighted := pg.Highlighted[0]
+		return highlighted != pg.Root && !highlighted.Kind.IsExponential()
+	}
+	return true
+}
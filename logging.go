@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"image/color"
+	"os"
+	"vll/eventlog"
+	"vll/page"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+)
+
+var (
+	logFile  = flag.String("log", "", "also write the event log to this file")
+	logLevel = flag.String("log-level", "info", "the least important events to log: debug, info, warn or error")
+)
+
+const (
+	// how many events are shown in the sidebar
+	logLines = 15
+	// how many characters of each event fit in the sidebar
+	logWidth = 31
+)
+
+// setupLog applies the log flags, and returns a function to close the log file with
+func setupLog() (func(), error) {
+	level, err := eventlog.ParseLevel(*logLevel)
+	if err != nil {
+		return nil, err
+	}
+	page.Log.SetLevel(level)
+	if *logFile == "" {
+		return func() {}, nil
+	}
+	f, err := os.OpenFile(*logFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	page.Log.SetOutput(f)
+	return func() {
+		page.Log.SetOutput(nil)
+		f.Close()
+	}, nil
+}
+
+// drawLog shows the latest events at the bottom of the sidebar
+func drawLog(win *pixelgl.Window, atlas *text.Atlas) {
+	txt := text.New(pixel.V(5, 10+float64(logLines)*atlas.LineHeight()), atlas)
+	txt.Color = color.RGBA{200, 200, 200, 255}
+	for _, e := range page.Log.Recent(logLines) {
+		line := e.Short()
+		if runes := []rune(line); len(runes) > logWidth {
+			line = string(runes[:logWidth-1]) + "~"
+		}
+		fmt.Fprintln(txt, line)
+	}
+	txt.Draw(win, pixel.IM)
+}
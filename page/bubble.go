@@ -3,48 +3,53 @@ package page
 import (
 	"container/list"
 	"fmt"
-	"image/color"
 	"math"
 	"math/rand"
 	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"vll/eventlog"
 )
 
 const (
 	seed        = 123
-	width       = 1024
-	height      = 720
+	width       = 1024 // default size of the screen, when there is no window
+	height      = 640
 	sidebar     = 225
 	circSquared = 900.0
 	alphabet    = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 )
 
-var (
-	WHITE = Kind(color.White)
-	BLACK = Kind(color.Black)
-	BLUE  = Kind(color.RGBA{
-		17,
-		205,
-		205,
-		255,
-	})
-	RED = Kind(color.RGBA{
-		238,
-		50,
-		50,
-		255,
-	})
-	BACKGROUND = Kind(color.RGBA{
-		151,
-		151,
-		184,
-		255,
-	})
-)
+// ID identifies a bubble for as long as the program runs. A bubble keeps its
+// ID in the snapshots of a proof's history, when a step is undone, and when
+// it's saved and loaded again; only duplicates of it, pasted or contracted,
+// get new IDs.
+type ID uint64
+
+func (id ID) String() string {
+	return "#" + strconv.FormatUint(uint64(id), 10)
+}
+
+// the last ID given to a bubble
+var lastID uint64
+
+func nextID() ID {
+	return ID(atomic.AddUint64(&lastID, 1))
+}
 
-type Kind color.Color
+// useID makes sure an ID read back in is never given to another bubble
+func useID(id ID) {
+	for {
+		last := atomic.LoadUint64(&lastID)
+		if uint64(id) <= last || atomic.CompareAndSwapUint64(&lastID, last, uint64(id)) {
+			return
+		}
+	}
+}
 
 type Bubble struct {
+	ID             ID
 	X              int
 	Y              int
 	VX             int
@@ -68,35 +73,8 @@ func init() {
 	rand.Seed(seed)
 }
 
-func (b *Bubble) OppositeKind() Kind {
-	switch b.Kind {
-	case BLACK:
-		return WHITE
-	case WHITE, BACKGROUND:
-		return BLACK
-	case RED:
-		return BLUE
-	case BLUE:
-		return RED
-	default:
-		return BACKGROUND
-	}
-}
-
 func (b *Bubble) IsMult() bool {
-	return b.Kind == BLACK || b.Kind == WHITE
-}
-
-func (b *Bubble) OppositePolarity() Kind {
-	switch b.Kind {
-	case BLACK, RED:
-		return WHITE
-	case WHITE, BLUE, BACKGROUND:
-		return BLACK
-
-	default:
-		return BACKGROUND
-	}
+	return b.Kind.IsMultiplicative()
 }
 
 // TODO: use more efficient algorithm, segmented tree is probably a good fit
@@ -130,6 +108,8 @@ func LCA(bubs ...*Bubble) *Bubble {
 	return LCA(LCA(bubs[0:len(bubs)/2]...), LCA(bubs[len(bubs)/2:]...))
 }
 
+// Copy duplicates a bubble and everything inside it, giving the duplicates
+// new IDs
 func (b *Bubble) Copy() *Bubble {
 	// create a new bubble
 	newb := newBubble(b.X, b.Y, b.Variable, b.Kind)
@@ -141,29 +121,25 @@ func (b *Bubble) Copy() *Bubble {
 	return newb
 }
 
-func Random(min, max int) int {
-	return min + rand.Intn(max-min)
+// clone copies a bubble and everything inside it, keeping their IDs, so that
+// the copy can stand in for the original
+func clone(b *Bubble) *Bubble {
+	c := &Bubble{ID: b.ID, X: b.X, Y: b.Y, Depth: b.Depth, Height: b.Height, Kind: b.Kind, Variable: b.Variable}
+	for _, child := range b.Children {
+		twin := clone(child)
+		twin.Parent = c
+		c.Children = append(c.Children, twin)
+	}
+	return c
 }
 
-func Name(k Kind) string {
-	switch k {
-	case WHITE:
-		return "White"
-	case BLACK:
-		return "Black"
-	case BLUE:
-		return "Blue"
-	case RED:
-		return "Red"
-	case BACKGROUND:
-		return "Root"
-	default:
-		return "Unknown"
-	}
+func Random(min, max int) int {
+	return min + rand.Intn(max-min)
 }
 
 func newBubble(x, y int, v string, k Kind) *Bubble {
 	return &Bubble{
+		ID:       nextID(),
 		X:        x,
 		Y:        y,
 		Kind:     k,
@@ -189,7 +165,7 @@ func (b *Bubble) Sprint() string {
 		if bub.Variable != "" {
 			s += bub.Variable
 		} else {
-			s += Name(bub.Kind)
+			s += bub.Kind.String()
 		}
 		s += fmt.Sprintf("(%v)", bub.Height)
 		s += "\n"
@@ -211,6 +187,13 @@ func (b *Bubble) Tolestra() string {
 			}
 			return "~" + b.Variable
 		}
+		// an empty loop holds the unit of its color
+		if b.Kind == BLUE {
+			return "!1"
+		}
+		if b.Kind == RED {
+			return "?0"
+		}
 	}
 
 	childrenStrings := make([]string, 0, len(b.Children))
@@ -244,6 +227,44 @@ func (b *Bubble) Tolestra() string {
 	return str
 }
 
+// Pretty is the bubble written the way linear logic usually is, with ⊗ and ⅋
+// between what's in White and Black bubbles, ⊥ for an empty Black bubble and
+// ¬ in front of negated variables. ParseTolestra reads it back.
+func (b *Bubble) Pretty() string {
+	if len(b.Children) == 0 {
+		switch {
+		case b.Kind == WHITE && b.Variable != "":
+			return b.Variable
+		case b.Kind == BLACK && b.Variable != "":
+			return "¬" + b.Variable
+		case b.Kind == WHITE:
+			return "1"
+		case b.Kind == BLACK:
+			return "⊥"
+		case b.Kind == BLUE:
+			return "!1"
+		case b.Kind == RED:
+			return "?⊥"
+		}
+		return ""
+	}
+
+	childrenStrings := make([]string, 0, len(b.Children))
+	for _, child := range b.Children {
+		childrenStrings = append(childrenStrings, child.Pretty())
+	}
+	sort.Strings(childrenStrings)
+
+	str := strings.Join(childrenStrings, " "+b.Kind.Polarity().Connective()+" ")
+	if len(b.Children) > 1 {
+		str = "(" + str + ")"
+	}
+	if b.Kind.IsExponential() {
+		str = b.Kind.Connective() + str
+	}
+	return str
+}
+
 func (b *Bubble) Opposite() string {
 	if len(b.Children) == 0 {
 		if b.Kind == BLACK {
@@ -258,6 +279,12 @@ func (b *Bubble) Opposite() string {
 			}
 			return "~" + b.Variable
 		}
+		if b.Kind == BLUE {
+			return "?0"
+		}
+		if b.Kind == RED {
+			return "!1"
+		}
 	}
 
 	childrenStrings := make([]string, 0, len(b.Children))
@@ -292,6 +319,22 @@ func (b *Bubble) Opposite() string {
 	return str
 }
 
+// structure describes the shape of the tree, including redundant loops that
+// Tolestra leaves out, but not where the bubbles are or what order they're in
+func (b *Bubble) structure() string {
+	childrenStrings := make([]string, 0, len(b.Children))
+	for _, child := range b.Children {
+		childrenStrings = append(childrenStrings, child.structure())
+	}
+	sort.Strings(childrenStrings)
+	return b.Kind.String() + strconv.Quote(b.Variable) + "(" + strings.Join(childrenStrings, " ") + ")"
+}
+
+// SameAs returns whether two trees have the same structure
+func (b *Bubble) SameAs(other *Bubble) bool {
+	return b.structure() == other.structure()
+}
+
 func (b *Bubble) bfs(f func(*Bubble)) {
 	queue := list.New()
 	queue.PushFront(b)
@@ -304,13 +347,16 @@ func (b *Bubble) bfs(f func(*Bubble)) {
 	}
 }
 
+// Insert makes child a child of b, keeping the depths and heights of both
+// subtrees correct. A bubble can't be inserted into itself or anything
+// inside it, in which case nothing happens and nil is returned.
 func (b *Bubble) Insert(child *Bubble) *Bubble {
 	if b == nil {
 		return nil
 	}
 	if child.IsAbove(b) {
+		Log.Error("insert", Field("parent", b), Field("child", child), eventlog.F("result", "cycle"))
 		return nil
-		panic("cyclic tree formed!")
 	}
 	for _, kiddo := range b.Children {
 		if kiddo == child {
@@ -318,12 +364,25 @@ func (b *Bubble) Insert(child *Bubble) *Bubble {
 		}
 	}
 	child.Depth = b.Depth + 1
+	child.normalizeDepth()
 	child.Parent = b
 	b.Children = append(b.Children, child)
+	b.fixHeights()
 
 	return child
 }
 
+// fixHeights recalculates the heights of b and its ancestors, after b's children changed
+func (b *Bubble) fixHeights() {
+	for ancestor := b; ancestor != nil; ancestor = ancestor.Parent {
+		height := ancestor.Height
+		ancestor.normalizeHeight()
+		if ancestor != b && ancestor.Height == height {
+			return
+		}
+	}
+}
+
 func (b *Bubble) normalizeHeight() {
 	if len(b.Children) == 0 {
 		b.Height = 0
@@ -339,8 +398,16 @@ func (b *Bubble) normalizeHeight() {
 	b.Height = height + 1
 }
 
+// normalizeDepth fixes the depths of every bubble below b
+func (b *Bubble) normalizeDepth() {
+	for _, child := range b.Children {
+		child.Depth = b.Depth + 1
+		child.normalizeDepth()
+	}
+}
+
 func (b *Bubble) Detach(child *Bubble) {
-	fmt.Println("detaching")
+	Log.Debug("detach", Field("parent", b), Field("child", child))
 	if b == nil {
 		return
 	}
@@ -351,7 +418,11 @@ func (b *Bubble) Detach(child *Bubble) {
 				b.Children[len(b.Children)-1], b.Children[i] = b.Children[i], b.Children[len(b.Children)-1]
 				b.Children = b.Children[:len(b.Children)-1]
 
+				// the child is the top of its own tree now
 				child.Parent = nil
+				child.Depth = 0
+				child.normalizeDepth()
+				b.fixHeights()
 				return
 			}
 		}
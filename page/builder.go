@@ -0,0 +1,131 @@
+package page
+
+import (
+	"fmt"
+
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// Formula is a statement built up from its connectives in Go, rather than
+// parsed from Tolestra's notation:
+//
+//	Tensor(Atom("A"), Par(Neg("B"), One()))
+//
+// is the same as "(A * (~B + 1))". Turning it into bubbles picks the color of
+// every bubble, and only adds the loops that are needed for each variable to
+// be in a bubble of its own color.
+type Formula struct {
+	f *formula
+}
+
+// Atom is a variable. It panics if the name isn't one Tolestra's notation
+// would read back as a variable, like "", "A B" or "1".
+func Atom(name string) Formula {
+	mustBeName(name)
+	return Formula{&formula{op: 'a', name: name}}
+}
+
+// Neg is a negated variable, and panics on the same names as Atom
+func Neg(name string) Formula {
+	mustBeName(name)
+	return Formula{&formula{op: 'a', name: name, neg: true}}
+}
+
+func mustBeName(name string) {
+	if !isName(name) {
+		panic(fmt.Sprintf("page: %q can't be the name of a variable", name))
+	}
+}
+
+// One is the unit of tensor, written 1
+func One() Formula {
+	return Formula{&formula{op: '1'}}
+}
+
+// Bottom is the unit of par, written 0
+func Bottom() Formula {
+	return Formula{&formula{op: '0'}}
+}
+
+// Tensor joins formulas with tensor. The tensor of nothing is One, and the
+// tensor of a single formula is just that formula, so that it doesn't get a
+// White bubble of its own.
+func Tensor(fs ...Formula) Formula {
+	return connect('*', One(), fs)
+}
+
+// Par joins formulas with par. The par of nothing is Bottom, and the par of
+// a single formula is just that formula.
+func Par(fs ...Formula) Formula {
+	return connect('+', Bottom(), fs)
+}
+
+// Implies is linear implication, which is the par of the dual of the premise
+// with the conclusion
+func Implies(premise, conclusion Formula) Formula {
+	return Par(premise.Dual(), conclusion)
+}
+
+func connect(op rune, unit Formula, fs []Formula) Formula {
+	switch len(fs) {
+	case 0:
+		return unit
+	case 1:
+		return fs[0]
+	}
+	f := &formula{op: op}
+	for _, arg := range fs {
+		f.args = append(f.args, arg.f)
+	}
+	return Formula{f}
+}
+
+// OfCourse puts a formula in a Blue loop
+func OfCourse(f Formula) Formula {
+	return Formula{&formula{op: '!', args: []*formula{f.f}}}
+}
+
+// WhyNot puts a formula in a Red loop
+func WhyNot(f Formula) Formula {
+	return Formula{&formula{op: '?', args: []*formula{f.f}}}
+}
+
+// Dual is the negation of the formula, pushed all the way down to its
+// variables, so that negating twice gives back the same formula
+func (f Formula) Dual() Formula {
+	return Formula{f.f.dual()}
+}
+
+// Bubble turns the formula into bubbles ready to be placed into a White
+// bubble, laid out around (0, 0) like ParseTolestra's
+func (f Formula) Bubble() *Bubble {
+	b := f.f.bubble(WHITE)
+	layout(b, 0, 0)
+	return b
+}
+
+// String is the formula in Tolestra's notation
+func (f Formula) String() string {
+	return f.Bubble().Tolestra()
+}
+
+// Statement is the whole tree of bubbles stating the formulas side by side:
+// the background, with a White bubble holding them
+func Statement(fs ...Formula) *Bubble {
+	root := newBubble(0, 0, "", BACKGROUND)
+	w := root.Insert(newBubble(0, 0, "", WHITE))
+	for _, f := range fs {
+		w.Insert(f.f.bubble(WHITE))
+	}
+	layout(w, 0, 0)
+	root.normalizeDepth()
+	return root
+}
+
+// NewFormulaPage makes a page in create mode stating the formulas, ready to
+// be proved
+func NewFormulaPage(win *pixelgl.Window, fs ...Formula) *Page {
+	pg := NewPage(win)
+	pg.setRoot(Statement(fs...))
+	return pg
+}
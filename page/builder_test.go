@@ -0,0 +1,71 @@
+package page
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+	"gotest.tools/assert/cmp"
+)
+
+func TestBuilder(t *testing.T) {
+	cases := []struct {
+		statement string
+		built     []Formula
+	}{
+		{"A", []Formula{Atom("A")}},
+		{"~A", []Formula{Neg("A")}},
+		{"(A * (~B + 1))", []Formula{Tensor(Atom("A"), Par(Neg("B"), One()))}},
+		{"A -o A", []Formula{Implies(Atom("A"), Atom("A"))}},
+		{"!(A * B)", []Formula{OfCourse(Tensor(Atom("A"), Atom("B")))}},
+		{"?(~A + B)", []Formula{WhyNot(Par(Neg("A"), Atom("B")))}},
+		{"?A", []Formula{WhyNot(Atom("A"))}},
+		{"!~A", []Formula{OfCourse(Neg("A"))}},
+		{"~(A * !B)", []Formula{Tensor(Atom("A"), OfCourse(Atom("B"))).Dual()}},
+		{"A, ~A", []Formula{Atom("A"), Neg("A")}},
+		{"", nil},
+		// a connective of a single formula is just that formula, without a redundant bubble around it
+		{"A", []Formula{Tensor(Par(Atom("A")))}},
+		{"(A + B)", []Formula{Tensor(Par(Atom("A"), Atom("B")))}},
+		{"1", []Formula{Tensor()}},
+		{"0", []Formula{Par()}},
+		{"A", []Formula{Atom("A").Dual().Dual()}},
+	}
+	for _, c := range cases {
+		t.Run(c.statement, func(t *testing.T) {
+			parsed, err := NewProofPage(nil, c.statement)
+			assert.NilError(t, err)
+			pg := NewFormulaPage(nil, c.built...)
+			assert.NilError(t, pg.Validate())
+			assert.Assert(t, pg.Root.SameAs(parsed.Root), "built %v, parsed %v", pg.Root.Tolestra(), parsed.Root.Tolestra())
+			if len(c.built) == 1 {
+				assert.Equal(t, c.built[0].String(), parsed.Root.Tolestra())
+			}
+		})
+	}
+}
+
+func TestBuiltProof(t *testing.T) {
+	pg := NewFormulaPage(nil, Par(Atom("A"), Neg("A")))
+	assert.Equal(t, pg.Mode, CreateMode)
+	assert.NilError(t, pg.SetMode(ProofMode))
+	assert.Assert(t, len(pg.LegalSteps("A")) > 0)
+
+	// every variable ends up in a bubble of its own color
+	pg.Root.Iterate(func(b *Bubble) {
+		if b.Variable != "" {
+			assert.Equal(t, b.Parent.Kind.Polarity(), b.Kind, "%v", b)
+		}
+	})
+}
+
+func TestBuilderNames(t *testing.T) {
+	for _, name := range []string{"A", "B_1", "A'", "x2", "10", "λ"} {
+		assert.Equal(t, Atom(name).String(), name)
+		assert.Equal(t, Neg(name).String(), "~"+name)
+	}
+	// names which wouldn't be read back as the same variable
+	for _, name := range []string{"", "A B", "1", "0", "A*B", "~A", "(A)", "A,B"} {
+		assert.Assert(t, cmp.Panics(func() { Atom(name) }), "%q", name)
+		assert.Assert(t, cmp.Panics(func() { Neg(name) }), "%q", name)
+	}
+}
@@ -0,0 +1,63 @@
+package page
+
+import "math"
+
+const (
+	minZoom = 0.2
+	maxZoom = 5.0
+)
+
+// Camera maps between world coordinates, which is where bubbles live, and
+// screen coordinates, which start at the top left corner of the window and
+// grow to the right and downwards.
+type Camera struct {
+	// the world coordinates shown at the top left corner of the screen
+	X float64
+	Y float64
+	// how many screen pixels one unit of world distance takes up
+	Zoom float64
+
+	Width  int
+	Height int
+}
+
+// NewCamera creates a camera for a screen of the given size, where world and
+// screen coordinates start out lined up exactly.
+func NewCamera(w, h int) *Camera {
+	return &Camera{Zoom: 1, Width: w, Height: h}
+}
+
+// Resize changes the size of the screen, keeping the top left corner in place
+func (c *Camera) Resize(w, h int) {
+	c.Width, c.Height = w, h
+}
+
+// ToWorld converts screen coordinates to world coordinates
+func (c *Camera) ToWorld(sx, sy int) (x int, y int) {
+	x = int(math.Round(c.X + float64(sx)/c.Zoom))
+	y = int(math.Round(c.Y + float64(sy)/c.Zoom))
+	return
+}
+
+// ToScreen converts world coordinates to screen coordinates
+func (c *Camera) ToScreen(x, y int) (sx float64, sy float64) {
+	sx = (float64(x) - c.X) * c.Zoom
+	sy = (float64(y) - c.Y) * c.Zoom
+	return
+}
+
+// Pan moves the view by the given distance in screen pixels, so that the
+// world appears to be dragged along with the mouse
+func (c *Camera) Pan(dx, dy float64) {
+	c.X -= dx / c.Zoom
+	c.Y -= dy / c.Zoom
+}
+
+// ZoomAt scales the view by factor, keeping the world point under the given
+// screen coordinates fixed
+func (c *Camera) ZoomAt(sx, sy int, factor float64) {
+	zoom := math.Max(minZoom, math.Min(maxZoom, c.Zoom*factor))
+	c.X += float64(sx)/c.Zoom - float64(sx)/zoom
+	c.Y += float64(sy)/c.Zoom - float64(sy)/zoom
+	c.Zoom = zoom
+}
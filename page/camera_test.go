@@ -0,0 +1,63 @@
+package page
+
+import (
+	"math"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCameraRoundTrip(t *testing.T) {
+	for _, zoom := range []float64{minZoom, 0.5, 1, 2, 3.5, maxZoom} {
+		c := NewCamera(width, height)
+		c.X, c.Y, c.Zoom = -123.25, 47.5, zoom
+		for _, p := range [][2]int{{0, 0}, {300, 200}, {-75, 910}, {1234, -56}} {
+			// a point on the screen is never more than half a world unit from
+			// where it's put back
+			wx, wy := c.ToWorld(p[0], p[1])
+			sx, sy := c.ToScreen(wx, wy)
+			assert.Assert(t, math.Abs(sx-float64(p[0])) <= zoom/2+1e-9, "zoom %v, %v: %v", zoom, p, sx)
+			assert.Assert(t, math.Abs(sy-float64(p[1])) <= zoom/2+1e-9, "zoom %v, %v: %v", zoom, p, sy)
+
+			// and when zoomed in, there are enough pixels to get every point
+			// in the world back exactly
+			if zoom > 1 {
+				sx, sy := c.ToScreen(p[0], p[1])
+				x, y := c.ToWorld(int(math.Round(sx)), int(math.Round(sy)))
+				assert.Equal(t, [2]int{x, y}, p, "zoom %v", zoom)
+			}
+		}
+	}
+}
+
+func TestCameraZoomAt(t *testing.T) {
+	c := NewCamera(width, height)
+	c.Pan(-200, 100)
+	for _, step := range []struct {
+		sx, sy int
+		factor float64
+	}{{600, 360, 2}, {100, 700, 1.5}, {900, 50, 0.25}, {0, 0, 3}, {450, 450, 100}, {450, 450, 0.001}} {
+		x := c.X + float64(step.sx)/c.Zoom
+		y := c.Y + float64(step.sy)/c.Zoom
+		c.ZoomAt(step.sx, step.sy, step.factor)
+
+		// the point under the mouse stays put
+		assert.Assert(t, math.Abs((x-c.X)*c.Zoom-float64(step.sx)) < 1e-6, "%+v", step)
+		assert.Assert(t, math.Abs((y-c.Y)*c.Zoom-float64(step.sy)) < 1e-6, "%+v", step)
+		wx, wy := c.ToWorld(step.sx, step.sy)
+		assert.Equal(t, [2]int{wx, wy}, [2]int{int(math.Round(x)), int(math.Round(y))}, "%+v", step)
+		assert.Assert(t, c.Zoom >= minZoom && c.Zoom <= maxZoom, "%+v: zoom %v", step, c.Zoom)
+	}
+	assert.Equal(t, c.Zoom, minZoom)
+}
+
+func TestCameraPan(t *testing.T) {
+	c := NewCamera(width, height)
+	c.ZoomAt(0, 0, 2)
+	x, y := c.ToWorld(400, 300)
+	// the world is dragged along with the mouse
+	c.Pan(50, -20)
+	sx, sy := c.ToScreen(x, y)
+	assert.Equal(t, sx, 450.0)
+	assert.Equal(t, sy, 280.0)
+}
@@ -0,0 +1,175 @@
+package page
+
+import (
+	"errors"
+	"strings"
+)
+
+// outermostSelected is the selected bubbles which aren't inside other selected bubbles
+func (pg *Page) outermostSelected() []*Bubble {
+	var outermost []*Bubble
+	for _, b := range pg.Highlighted {
+		inside := false
+		for _, other := range pg.Highlighted {
+			if other != b && other.IsAbove(b) {
+				inside = true
+			}
+		}
+		if !inside {
+			outermost = append(outermost, b)
+		}
+	}
+	return outermost
+}
+
+// CheckCopy returns why the selection can't be copied to the clipboard, or nil if it can
+func (pg *Page) CheckCopy() error {
+	if len(pg.Highlighted) == 0 {
+		return errNothingSelected
+	}
+	for _, b := range pg.outermostSelected() {
+		if b == pg.Root {
+			return errors.New("the background can't be copied")
+		}
+		if pg.isAssumptionPair(b) {
+			return errors.New("the assumption pair can't be copied")
+		}
+		switch pg.Mode {
+		case ProofMode:
+			// copying a blue loop is contraction
+			if b.Kind != BLUE {
+				return errors.New("only blue loops can be copied while proving")
+			}
+		case ContingencyMode:
+			if !pg.InContingency(b) {
+				return errors.New("only things inside the contingency can be copied")
+			}
+		}
+	}
+	return nil
+}
+
+// CopySelection replaces the clipboard with copies of the selected bubbles
+func (pg *Page) CopySelection() {
+	pg.Clipboard = nil
+	for _, b := range pg.outermostSelected() {
+		pg.Clipboard = append(pg.Clipboard, b.Copy())
+	}
+}
+
+// CheckCut returns why the selection can't be cut, or nil if it can. While
+// proving, only blue loops can be cut, which is weakening.
+func (pg *Page) CheckCut() error {
+	return pg.CheckCopy()
+}
+
+// CutSelection copies the selection to the clipboard, and then deletes it
+// along with everything inside it
+func (pg *Page) CutSelection() {
+	pg.CopySelection()
+	for _, b := range pg.outermostSelected() {
+		pg.Delete(b)
+	}
+	pg.Highlighted = nil
+}
+
+// pasteTarget is the bubble things pasted onto b go into
+func pasteTarget(b *Bubble) *Bubble {
+	if b.Variable != "" && b.Parent != nil {
+		return b.Parent
+	}
+	return b
+}
+
+// CheckPaste returns why the clipboard can't be pasted into a bubble, or nil if it can
+func (pg *Page) CheckPaste(parent *Bubble) error {
+	if len(pg.Clipboard) == 0 {
+		return errors.New("the clipboard is empty")
+	}
+	parent = pasteTarget(parent)
+	switch pg.Mode {
+	case ProofMode:
+		// pasting is only contraction if the copy goes next to the original
+		for _, b := range pg.Clipboard {
+			if b.Kind != BLUE || !hasChildLike(parent, b) {
+				return errors.New("while proving, a blue loop can only be pasted next to the loop it was copied from")
+			}
+		}
+	case ContingencyMode:
+		if parent != pg.Contingency && !pg.InContingency(parent) {
+			return errors.New("things can only be pasted inside the contingency")
+		}
+	case AssumptionMode:
+		return errors.New("can't paste while making an assumption")
+	}
+	return nil
+}
+
+func hasChildLike(parent, b *Bubble) bool {
+	for _, child := range parent.Children {
+		if child.SameAs(b) {
+			return true
+		}
+	}
+	return false
+}
+
+// Paste puts copies of the clipboard into a bubble, centered on (x, y), and selects them
+func (pg *Page) Paste(parent *Bubble, x, y int) {
+	pg.pasteCopies(parent, pg.Clipboard, x, y)
+}
+
+// pasteCopies puts copies of some bubbles into a bubble, centered on (x, y),
+// and selects them
+func (pg *Page) pasteCopies(parent *Bubble, bubbles []*Bubble, x, y int) {
+	parent = pasteTarget(parent)
+	var pasted []*Bubble
+	for _, b := range bubbles {
+		pasted = append(pasted, b.Copy())
+	}
+
+	// move everything so that it's centered where it's pasted
+	var cx, cy int
+	for _, b := range pasted {
+		bx, by := b.CenterOfMass()
+		cx += bx
+		cy += by
+	}
+	cx /= len(pasted)
+	cy /= len(pasted)
+
+	for i, b := range pasted {
+		b.Iterate(func(bub *Bubble) {
+			bub.X += x - cx
+			bub.Y += y - cy
+		})
+		// variables need a bubble of their own color around them
+		if b.Variable != "" && b.IsMult() && parent.Kind.Polarity() != b.Kind {
+			loop := newBubble(b.X, b.Y, "", b.Kind)
+			loop.Insert(b)
+			pasted[i] = loop
+		}
+		pg.Place(parent, pasted[i])
+		pasted[i].normalizeDepth()
+	}
+	pg.Highlighted = pasted
+}
+
+// ClipboardText is the clipboard in Tolestra's notation, with commas between the copied bubbles
+func (pg *Page) ClipboardText() string {
+	formulas := make([]string, 0, len(pg.Clipboard))
+	for _, b := range pg.Clipboard {
+		formulas = append(formulas, b.Tolestra())
+	}
+	return strings.Join(formulas, ", ")
+}
+
+// SetClipboardText replaces the clipboard with formulas written in Tolestra's notation
+func (pg *Page) SetClipboardText(s string) error {
+	bubbles, err := ParseTolestra(s)
+	if err != nil {
+		return err
+	}
+	pg.Clipboard = bubbles
+	return nil
+}
@@ -0,0 +1,67 @@
+package page
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestCopyPaste(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 100, 100, "", WHITE)
+	a := add(w, 100, 100, "A", WHITE)
+	b := add(w, 200, 100, "", BLACK)
+	add(b, 200, 100, "B", BLACK)
+
+	pg.Select(a, b)
+	assert.NilError(t, pg.CheckCopy())
+	pg.CopySelection()
+	assert.Equal(t, pg.ClipboardText(), "A, ~B")
+
+	assert.NilError(t, pg.CheckPaste(b))
+	pg.Execute(func() { pg.Paste(b, 300, 300) })
+	assert.Equal(t, pg.Root.Tolestra(), "((A + ~B + ~B) * A)")
+	assert.Equal(t, len(pg.Highlighted), 2)
+	for _, pasted := range pg.Highlighted {
+		assert.Equal(t, pasted.Parent, b)
+		assert.Equal(t, pasted.Depth, b.Depth+1)
+	}
+
+	pg.Select(b)
+	pg.Execute(pg.CutSelection)
+	assert.Equal(t, pg.Root.Tolestra(), "A")
+	assert.Equal(t, pg.ClipboardText(), "(A + ~B + ~B)")
+}
+
+func TestPasteText(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 100, 100, "", WHITE)
+	assert.Assert(t, pg.CheckPaste(w) != nil)
+	assert.ErrorContains(t, pg.SetClipboardText("A *"), "unexpected end")
+	assert.NilError(t, pg.SetClipboardText("A -o A"))
+	pg.Execute(func() { pg.Paste(w, 100, 100) })
+	assert.Equal(t, pg.Root.Tolestra(), "(A + ~A)")
+}
+
+func TestCopyWhileProving(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 100, 100, "", WHITE)
+	blue := add(w, 100, 100, "", BLUE)
+	add(blue, 100, 100, "A", WHITE)
+	b := add(w, 200, 100, "B", WHITE)
+	assert.NilError(t, pg.SetMode(ProofMode))
+
+	pg.Select(b)
+	assert.ErrorContains(t, pg.CheckCopy(), "only blue loops")
+
+	pg.Select(blue)
+	assert.NilError(t, pg.CheckCopy())
+	pg.CopySelection()
+
+	// contraction only puts the copy next to the original
+	assert.ErrorContains(t, pg.CheckPaste(pg.Root), "next to the loop")
+	assert.NilError(t, pg.CheckPaste(w))
+	pg.Execute(func() { pg.Paste(w, 100, 200) })
+	assert.Equal(t, pg.Root.Tolestra(), "(!A * !A * B)")
+	assert.Equal(t, len(pg.History), 2)
+}
@@ -0,0 +1,167 @@
+package page
+
+import (
+	"flag"
+	"sort"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+var searchDepth = flag.Int("search-depth", 4, "how many steps to search for proofs of non-theorems")
+
+// The editor works forwards: every step turns a statement into one of its
+// consequences, and a theorem is proved by building it up from nothing, with
+// assumptions playing the part of the identity axiom. Its logic is
+// multiplicative linear logic with the mix rule, since an empty region means
+// the same thing whatever its color.
+
+// theorems, each with a proof starting from the empty statement
+var theorems = []struct {
+	statement string
+	proof     string
+}{
+	{"A -o A", "loop [0]; assume [0 0] A"},
+	{"(A * B) -o (B * A)", "loop [0]; assume [0 0] A; assume [0 0] B; loop [0 1] [0 2]"},
+	{"((A * B) * C) -o (A * (B * C))", "loop [0]; assume [0 0] A; assume [0 0] B; assume [0 0] C; " +
+		"unit [0 0]; move [0 0 1] -> [0 0 3]; move [0 0 2] -> [0 0 1]; loop [0 1] [0 2]; loop [0 1] [0 2]"},
+	{"A * (A -o B) -o B", "loop [0]; assume [0 0] B; loop [0 1]; loop [0 1]; assume [0 1 0] A"},
+	// only true with mix
+	{"(A * B) -o (A + B)", "loop [0]; unit [0]; loop [0 1]; unit [0 1]; move [0 1 1] -> [0 1 0]; " +
+		"assume [0 0] A; assume [0 1] B; loop [0 0] [0 1]; loop [0 1] [0 2]"},
+	{"!1", "unit [0]; of-course [0 0]"},
+	{"!A -o A", "loop [0]; assume [0 0] A; why-not [0 1 0]"},
+	{"A -o ?A", "loop [0]; assume [0 0] A; why-not [0 0 0]"},
+}
+
+// statements which can't be proved
+var nonTheorems = []string{
+	"A",
+	"A -o B",
+	"A -o (A * A)",
+	"(A * B) -o A",
+	"(A + B) -o (A * B)",
+	"A -o !A",
+	"?A -o A",
+	"?A -o !A",
+	"!(A + B) -o (!A + !B)",
+	"(?A * ?B) -o ?(A * B)",
+}
+
+// emptyProofPage is a page proving the empty statement
+func emptyProofPage() *Page {
+	pg := NewPage(nil)
+	pg.Root.Insert(newBubble(0, 0, "", WHITE))
+	pg.reindex()
+	pg.SetMode(ProofMode)
+	return pg
+}
+
+// statement is how a formula is written once it's been put on a page
+func statement(t *testing.T, formula string) string {
+	pg, err := NewProofPage(nil, formula)
+	assert.NilError(t, err)
+	return pg.Root.Tolestra()
+}
+
+func TestTheorems(t *testing.T) {
+	for _, theorem := range theorems {
+		t.Run(theorem.statement, func(t *testing.T) {
+			pg := emptyProofPage()
+			for _, str := range strings.Split(theorem.proof, "; ") {
+				s, err := ParseStep(str)
+				assert.NilError(t, err)
+				_, err = pg.TakeStep(s)
+				assert.NilError(t, err, "can't take step %v, from %v", s, pg.Root.Tolestra())
+			}
+			assert.Equal(t, pg.Root.Tolestra(), statement(t, theorem.statement))
+
+			f := statementFormula(pg.Root)
+			assert.Assert(t, provable([]*formula{f}), "%v isn't a theorem", f)
+		})
+	}
+}
+
+// shape describes a statement exactly, units and all, unlike Tolestra's notation
+func shape(b *Bubble) string {
+	kids := make([]string, len(b.Children))
+	for i, child := range b.Children {
+		kids[i] = shape(child)
+	}
+	sort.Strings(kids)
+	return b.Kind.String() + b.Variable + "[" + strings.Join(kids, " ") + "]"
+}
+
+func size(b *Bubble) int {
+	n := 0
+	b.Iterate(func(*Bubble) { n++ })
+	return n
+}
+
+// reachable finds every statement that can be proved in at most the given
+// number of steps, keeping to statements of at most maxSize bubbles
+func reachable(depth, maxSize int, variables []string) map[string]*Bubble {
+	proved := make(map[string]*Bubble)
+	seen := make(map[string]bool)
+	frontier := []*Page{emptyProofPage()}
+	for d := 0; d <= depth; d++ {
+		var next []*Page
+		for _, pg := range frontier {
+			k := shape(pg.Root)
+			if seen[k] || size(pg.Root) > maxSize {
+				continue
+			}
+			seen[k] = true
+			proved[pg.Root.Tolestra()] = pg.Root
+			if d == depth {
+				continue
+			}
+			for _, s := range pg.LegalSteps(variables...) {
+				after := NewPage(nil)
+				after.setRoot(pg.Root)
+				after.Mode = ProofMode
+				after.History = []*Bubble{after.Root.Copy()}
+				if _, err := after.TakeStep(s); err == nil {
+					next = append(next, after)
+				}
+			}
+		}
+		frontier = next
+	}
+	return proved
+}
+
+func TestNonTheorems(t *testing.T) {
+	depth := *searchDepth
+	if testing.Short() {
+		depth--
+	}
+	proved := reachable(depth, 10, []string{"A", "B"})
+
+	// everything that can be proved has to be a theorem, including the
+	// statements the search reached with of-course, why-not and copy steps
+	exponential := 0
+	for s, root := range proved {
+		f := statementFormula(root)
+		assert.Assert(t, provable([]*formula{f}), "%v was proved, but isn't a theorem", s)
+		if hasExponentials(f) {
+			exponential++
+		}
+	}
+	assert.Assert(t, exponential > 0)
+	for _, nonTheorem := range nonTheorems {
+		s := statement(t, nonTheorem)
+		_, ok := proved[s]
+		assert.Assert(t, !ok, "%v was proved", nonTheorem)
+
+		f := statementFormula(proofPageRoot(t, nonTheorem))
+		assert.Assert(t, !provable([]*formula{f}), "%v is a theorem", nonTheorem)
+	}
+}
+
+func proofPageRoot(t *testing.T, formula string) *Bubble {
+	pg, err := NewProofPage(nil, formula)
+	assert.NilError(t, err)
+	return pg.Root
+}
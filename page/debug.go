@@ -0,0 +1,9 @@
+//go:build debug
+// +build debug
+
+package page
+
+// debug builds check the statement after everything that changes it
+func init() {
+	validateAfterExecute = true
+}
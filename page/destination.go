@@ -0,0 +1,108 @@
+package page
+
+import "vll/eventlog"
+
+// Effect is what moving a bubble somewhere does
+type Effect int
+
+const (
+	// Move puts the bubble into the destination
+	Move Effect = iota
+	// Annihilate removes the bubble along with the destination, which is its dual
+	Annihilate
+	// Wrap puts a loop of the destination's color around it, since it's a
+	// variable, and puts the bubble in the loop next to it
+	Wrap
+)
+
+func (e Effect) String() string {
+	switch e {
+	case Move:
+		return "move"
+	case Annihilate:
+		return "annihilate"
+	case Wrap:
+		return "wrap"
+	default:
+		return "unknown"
+	}
+}
+
+// Destination is somewhere a bubble can be moved to while proving, along with
+// what moving it there does
+type Destination struct {
+	Bubble *Bubble
+	// the bubble it's moved out of
+	From   *Bubble
+	Into   *Bubble
+	Effect Effect
+}
+
+// CheckMove returns what moving b into another bubble would do, or why it
+// isn't allowed
+func (pg *Page) CheckMove(b, into *Bubble) (Destination, error) {
+	return pg.destination(b, b.Parent, into)
+}
+
+// DropDestination returns what dropping the grabbed bubble into another
+// bubble would do, or why it isn't allowed. The grabbed bubble may have
+// already been pulled out of the bubble it was grabbed from.
+func (pg *Page) DropDestination(into *Bubble) (Destination, error) {
+	return pg.destination(pg.Grabbed, pg.GrabbedParent, into)
+}
+
+func (pg *Page) destination(b, from, into *Bubble) (Destination, error) {
+	annihilate, err := pg.checkMove(b, from, into)
+	if err != nil {
+		return Destination{}, err
+	}
+	d := Destination{Bubble: b, From: from, Into: into}
+	switch {
+	case annihilate:
+		d.Effect = Annihilate
+	case into.Variable != "" && into.Parent.Kind == into.Kind:
+		// there's already a bubble of the right color around the variable
+		d.Into = into.Parent
+	case into.Variable != "":
+		d.Effect = Wrap
+	}
+	return d, nil
+}
+
+// Destinations lists everywhere b can be moved to while proving. Moving it
+// onto a variable in a bubble of its own color does the same as moving it into
+// that bubble, so only the bubble is listed.
+func (pg *Page) Destinations(b *Bubble) []Destination {
+	var destinations []Destination
+	pg.Root.Iterate(func(into *Bubble) {
+		if into == b.Parent {
+			return
+		}
+		if d, err := pg.CheckMove(b, into); err == nil && d.Into == into {
+			destinations = append(destinations, d)
+		}
+	})
+	return destinations
+}
+
+// ApplyMove moves a bubble to a destination, as long as it's still allowed
+func (pg *Page) ApplyMove(d Destination) error {
+	if _, err := pg.checkMove(d.Bubble, d.From, d.Into); err != nil {
+		Log.Info("move", Field("bubble", d.Bubble), Field("into", d.Into), eventlog.F("result", "rejected"), eventlog.F("reason", err))
+		return err
+	}
+	switch d.Effect {
+	case Annihilate:
+		Log.Info("annihilate", Field("bubble", d.Bubble), Field("dual", d.Into))
+		d.Into.Parent.Detach(d.Into)
+		d.From.Detach(d.Bubble)
+		pg.Grabbed = nil
+		pg.GrabbedParent = nil
+		pg.Highlighted = nil
+	default:
+		Log.Info("move", Field("bubble", d.Bubble), Field("into", d.Into), eventlog.F("effect", d.Effect))
+		pg.Grabbed, pg.GrabbedParent = d.Bubble, d.From
+		pg.ReleaseInto(d.Into)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package page
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestDestinations(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	a := add(w, 0, 0, "A", WHITE)
+	b := add(w, 0, 0, "", BLACK)
+	notA := add(b, 0, 0, "A", BLACK)
+	inner := add(b, 0, 0, "", WHITE)
+	c := add(inner, 0, 0, "C", WHITE)
+	pg.SetMode(ProofMode)
+	before := pg.Root.Copy()
+
+	effects := make(map[*Bubble]Effect)
+	for _, d := range pg.Destinations(a) {
+		assert.Equal(t, d.Bubble, a)
+		assert.Equal(t, d.From, w)
+		effects[d.Into] = d.Effect
+	}
+	assert.DeepEqual(t, effects, map[*Bubble]Effect{notA: Annihilate, inner: Move})
+
+	// a variable in a bubble of another color gets a loop around it
+	pg.Delete(c)
+	pg.Place(b, c)
+	d, err := pg.CheckMove(a, c)
+	assert.NilError(t, err)
+	assert.Equal(t, d.Effect, Wrap)
+	pg.Delete(c)
+	pg.Place(inner, c)
+
+	// a variable in a bubble of its own color is the same as that bubble
+	d, err = pg.CheckMove(a, c)
+	assert.NilError(t, err)
+	assert.Equal(t, d, Destination{Bubble: a, From: w, Into: inner, Effect: Move})
+
+	// none of that changed anything
+	assert.Assert(t, pg.Root.SameAs(before))
+	assert.Assert(t, pg.Grabbed == nil)
+
+	pg.Execute(func() { assert.NilError(t, pg.ApplyMove(d)) })
+	assert.Equal(t, a.Parent, inner)
+	assert.Equal(t, len(pg.History), 2)
+
+	// a move that's no longer allowed isn't made
+	pg.Delete(a)
+	pg.Place(b, a)
+	assert.Assert(t, pg.ApplyMove(Destination{Bubble: a, From: b, Into: inner}) != nil)
+	assert.Equal(t, a.Parent, b)
+}
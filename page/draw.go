@@ -12,17 +12,27 @@ import (
 )
 
 func thresh(bub *Bubble, iter int) float64 {
-	n := float64(bub.Depth - iter - bub.Height)
+	return threshAt(bub, bub.Depth, iter)
+}
+
+// threshAt is thresh for a bubble drawn as if it were at the given depth
+func threshAt(bub *Bubble, depth, iter int) float64 {
+	n := float64(depth - iter - bub.Height)
 	if bub.Variable == "" {
 		n++
 	}
-	if bub.Kind == BLUE || bub.Kind == RED {
+	if bub.Kind.IsExponential() {
 		n += 0.5
 	}
 	return 0.5 * math.Pow(1.311, n)
 }
 
 func (pg *Page) childrenBoundary(b *Bubble, x, y int) float64 {
+	return pg.boundaryAt(b, b.Depth, x, y)
+}
+
+// boundaryAt is childrenBoundary for a bubble drawn as if it were at the given depth
+func (pg *Page) boundaryAt(b *Bubble, depth, x, y int) float64 {
 	squaredSum, closestD2 := 0.0, math.MaxFloat64
 
 	b.Iterate(func(bub *Bubble) {
@@ -37,7 +47,7 @@ func (pg *Page) childrenBoundary(b *Bubble, x, y int) float64 {
 
 		d2 := dx*dx + dy*dy
 
-		if b.Variable == "" && b.Depth > 1 && b.Height < 2 {
+		if b.Variable == "" && depth > 1 && b.Height < 2 {
 			squaredSum += 900.0 / d2
 
 			// keep track of the color and distance of the closest circle
@@ -56,53 +66,32 @@ func (pg *Page) childrenBoundary(b *Bubble, x, y int) float64 {
 
 	// the sum is the L^p norm of the distances from the pixel to the boundary of each circle
 	// sum := math.Sqrt(squaredSum)
+	if scale, ok := pg.scale[b]; ok {
+		squaredSum *= scale
+	}
 	return squaredSum
 }
 
 func (pg *Page) colorBubble(b *Bubble, x, y int) color.Color {
 	if b == nil {
-		return color.Black
+		return pg.Theme.Backdrop
 	}
-	clr := b.Kind
+	clr := pg.Theme.Color(b.Kind)
+	checkered := (x/pxSize-y/pxSize)%2 == 0
 	if pg.IsHighlighted(b) {
-		if b.Kind == WHITE && (x/pxSize-y/pxSize)%2 == 0 {
-			clr = color.RGBA{
-				R: 255,
-				G: 255,
-				B: 100,
-				A: 255,
-			}
-		}
-		if b.Kind == BLACK && (x/pxSize-y/pxSize)%2 == 0 {
-			clr = color.RGBA{
-				R: 50,
-				G: 0,
-				B: 135,
-				A: 255,
-			}
-		}
-		if b.Kind == BLUE && (x/pxSize-y/pxSize)%2 == 0 {
-			clr = color.RGBA{
-				R: 17,
-				G: 151,
-				B: 205,
-				A: 255,
-			}
+		if checkered {
+			clr = pg.Theme.Highlight(b.Kind)
 		}
-		if b.Kind == RED && (x/pxSize-y/pxSize)%2 == 0 {
-			clr = color.RGBA{
-				R: 238,
-				G: 104,
-				B: 50,
-				A: 255,
-			}
-		}
-	} else if pg.AssumptionMode {
+	} else if pg.Mode == AssumptionMode {
 		if !pg.AssumptionPair.Positive.IsAbove(b) && !pg.AssumptionPair.Negative.IsAbove(b) {
-			if (x/pxSize-y/pxSize)%2 == 0 {
-				clr = BACKGROUND
+			if checkered {
+				clr = pg.Theme.Color(BACKGROUND)
 			}
 		}
+	} else if pg.Mode == ContingencyMode {
+		if !pg.Contingency.IsAbove(b) && checkered {
+			clr = pg.Theme.Color(BACKGROUND)
+		}
 	}
 	return clr
 }
@@ -123,13 +112,21 @@ func (p *Page) BelongsTo(x, y int) *Bubble {
 }
 
 func (p *Page) BelongsToGrabbed(x, y int) *Bubble {
+	return p.belongsToGrabbed(x, y, p.grabbedDepth())
+}
+
+// belongsToGrabbed is BelongsToGrabbed with the grabbed bubble drawn at the
+// given depth, which only has to be worked out once a frame
+func (p *Page) belongsToGrabbed(x, y, depth int) *Bubble {
 	var owner *Bubble
 	owner = p.Root
 
+	shift := depth - p.Grabbed.Depth
 	p.Grabbed.bfs(func(bub *Bubble) {
-		dist := p.childrenBoundary(bub, x, y)
-		for i := bub.Depth; i > 0; i-- {
-			if dist > thresh(bub, i) {
+		d := bub.Depth + shift
+		dist := p.boundaryAt(bub, d, x, y)
+		for i := d; i > 0; i-- {
+			if dist > threshAt(bub, d, i) {
 				owner = bub
 			}
 		}
@@ -137,6 +134,17 @@ func (p *Page) BelongsToGrabbed(x, y int) *Bubble {
 	return owner
 }
 
+// grabbedDepth is how deep the grabbed bubble is drawn. One that's been yanked
+// out of the statement is the top of a tree of its own, where nothing of it
+// would show, so it's drawn as deep as it would be if it were dropped where
+// it is.
+func (p *Page) grabbedDepth() int {
+	if p.Grabbed.Parent != nil {
+		return p.Grabbed.Depth
+	}
+	return p.NearestAlternative(p.Grabbed.X, p.Grabbed.Y).Depth + 1
+}
+
 func (p *Page) NearestAlternative(x, y int) *Bubble {
 	var owner *Bubble
 	owner = p.Root
@@ -166,45 +174,61 @@ func (p *Page) NearestAlternative(x, y int) *Bubble {
 	return owner
 }
 
+// DrawPicture renders the page into a picture that can be drawn to the window.
 func (pg *Page) DrawPicture() *pixel.PictureData {
-	m := image.NewRGBA(image.Rect(0, 0, width, height))
-	draw.Draw(m, m.Bounds(), &image.Uniform{color.Black}, image.ZP, draw.Src)
+	return pixel.PictureDataFromImage(pg.RenderImage())
+}
+
+// RenderImage rasterizes the metaballs of the page into an image the size of
+// the screen. It doesn't touch the window, so it can be used for offscreen
+// rendering and tests.
+func (pg *Page) RenderImage() *image.RGBA {
+	m := image.NewRGBA(image.Rect(0, 0, pg.Camera.Width, pg.Camera.Height))
+	draw.Draw(m, m.Bounds(), &image.Uniform{pg.Theme.Backdrop}, image.ZP, draw.Src)
+	var grabbedDepth int
+	if pg.Grabbed != nil {
+		grabbedDepth = pg.grabbedDepth()
+	}
 
-	for x := sidebar; x < width; x += pxSize {
-		for y := 0; y < height; y += pxSize {
+	for sx := sidebar; sx < pg.Camera.Width; sx += pxSize {
+		for sy := 0; sy < pg.Camera.Height; sy += pxSize {
 			var clr color.Color
 
+			x, y := pg.Camera.ToWorld(sx, sy)
 			b := pg.BelongsTo(x, y)
-			clr = pg.colorBubble(b, x, y)
+			clr = pg.colorBubble(b, sx, sy)
 
-			rect := image.Rect(x, y, x+pxSize, y+pxSize)
+			rect := image.Rect(sx, sy, sx+pxSize, sy+pxSize)
 			draw.Draw(m, rect, &image.Uniform{clr}, image.ZP, draw.Src)
 
 			if pg.Grabbed != nil {
-				b = pg.BelongsToGrabbed(x, y)
+				b = pg.belongsToGrabbed(x, y, grabbedDepth)
 				if b != pg.Root {
-					clr = pg.colorBubble(b, x, y)
+					clr = pg.colorBubble(b, sx, sy)
 					draw.Draw(m, rect, &image.Uniform{clr}, image.ZP, draw.Src)
 				}
 			}
 		}
 	}
-	return pixel.PictureDataFromImage(m)
+	return m
 }
 
 func (pg *Page) drawLabel(b *Bubble) {
-	centerX := float64(b.X + 3)
-	if len(b.Variable) >= 1 {
-		centerX -= float64(14 * len(b.Variable))
+	sx, sy := pg.Camera.ToScreen(b.X, b.Y)
+	zoom := pg.Camera.Zoom
+	// leave the sidebar alone
+	if sx < sidebar {
+		return
 	}
-	basicTxt := text.New(pixel.V(centerX, height-float64(b.Y+55)), pg.Atlas)
-	if b.Kind == WHITE {
-		basicTxt.Color = color.Black
-	} else {
-		basicTxt.Color = color.White
+	centerX := sx + 3*zoom
+	if len(b.Variable) >= 1 {
+		centerX -= 14 * zoom * float64(len(b.Variable))
 	}
+	// pixel has its origin at the bottom left of the window
+	basicTxt := text.New(pixel.V(centerX, float64(pg.Camera.Height)-sy-15*zoom), pg.Atlas)
+	basicTxt.Color = pg.Theme.Label(b.Kind)
 	fmt.Fprintln(basicTxt, b.Variable)
-	basicTxt.Draw(pg.win, pixel.IM.Scaled(basicTxt.Orig, 4))
+	basicTxt.Draw(pg.win, pixel.IM.Scaled(basicTxt.Orig, 4*zoom))
 }
 
 func (pg *Page) Label() {
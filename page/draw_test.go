@@ -0,0 +1,198 @@
+package page
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// run `go test ./page -update` to regenerate the golden images after an
+// intentional change to the renderer
+var update = flag.Bool("update", false, "update golden images in testdata")
+
+const (
+	// how far apart two channel values can be before the pixel counts as different
+	channelTolerance = 8
+	// how many differing pixels are allowed before the images count as different
+	pixelTolerance = 0.001
+)
+
+// add inserts a new bubble into parent, and returns the new bubble
+func add(parent *Bubble, x, y int, v string, k Kind) *Bubble {
+	return parent.Insert(newBubble(x, y, v, k))
+}
+
+func TestRender(t *testing.T) {
+	cases := []struct {
+		name  string
+		build func(pg *Page)
+	}{
+		{"empty", func(pg *Page) {}},
+		{"unit", func(pg *Page) {
+			add(pg.Root, 600, 360, "", WHITE)
+		}},
+		{"atom", func(pg *Page) {
+			w := add(pg.Root, 600, 360, "", WHITE)
+			add(w, 600, 360, "A", WHITE)
+		}},
+		{"tensor", func(pg *Page) {
+			w := add(pg.Root, 600, 360, "", WHITE)
+			add(w, 520, 360, "A", WHITE)
+			add(w, 680, 360, "B", WHITE)
+		}},
+		{"identity", func(pg *Page) {
+			// A -o A
+			w := add(pg.Root, 600, 360, "", WHITE)
+			b := add(w, 600, 360, "", BLACK)
+			add(b, 500, 360, "A", BLACK)
+			inner := add(b, 700, 360, "", WHITE)
+			add(inner, 700, 360, "A", WHITE)
+		}},
+		{"exponentials", func(pg *Page) {
+			w := add(pg.Root, 600, 360, "", WHITE)
+			blue := add(w, 480, 360, "", BLUE)
+			add(blue, 480, 360, "A", WHITE)
+			b := add(w, 720, 360, "", BLACK)
+			red := add(b, 720, 360, "", RED)
+			add(red, 720, 360, "B", BLACK)
+		}},
+		{"long_variable", func(pg *Page) {
+			w := add(pg.Root, 600, 360, "", WHITE)
+			add(w, 600, 360, "ABCD", WHITE)
+		}},
+		{"highlighted", func(pg *Page) {
+			w := add(pg.Root, 600, 360, "", WHITE)
+			a := add(w, 520, 360, "A", WHITE)
+			b := add(w, 680, 360, "", BLACK)
+			pg.Highlighted = []*Bubble{a, b}
+		}},
+		{"assumption", func(pg *Page) {
+			w := add(pg.Root, 600, 360, "", WHITE)
+			b := add(w, 600, 360, "", BLACK)
+			positive := add(b, 500, 360, "", WHITE)
+			negative := add(b, 700, 360, "", BLACK)
+			positive.AssumptionPair = negative
+			negative.AssumptionPair = positive
+			pg.AssumptionPair = &Pair{Positive: positive, Negative: negative}
+			pg.Mode = AssumptionMode
+		}},
+		{"contingency", func(pg *Page) {
+			w := add(pg.Root, 600, 360, "", WHITE)
+			add(w, 500, 360, "A", WHITE)
+			red := add(w, 700, 360, "", RED)
+			add(red, 700, 360, "", WHITE)
+			pg.Mode = ContingencyMode
+			pg.Contingency = red
+		}},
+		{"grabbed", func(pg *Page) {
+			// yanked out of the White bubble it's being dragged over, the way
+			// the editor does it
+			w := add(pg.Root, 500, 360, "", WHITE)
+			add(w, 500, 360, "A", WHITE)
+			grabbed := add(w, 750, 400, "B", WHITE)
+			pg.Grab(grabbed, 750, 400)
+			pg.Delete(grabbed)
+			pg.Highlighted = []*Bubble{grabbed}
+		}},
+		{"zoomed", func(pg *Page) {
+			w := add(pg.Root, 600, 360, "", WHITE)
+			add(w, 520, 360, "A", WHITE)
+			add(w, 680, 360, "B", WHITE)
+			pg.Camera.ZoomAt(600, 360, 2)
+		}},
+		{"panned", func(pg *Page) {
+			w := add(pg.Root, 600, 360, "", WHITE)
+			add(w, 520, 360, "A", WHITE)
+			add(w, 680, 360, "B", WHITE)
+			pg.Camera.Pan(-200, 100)
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			pg := NewPage(nil)
+			c.build(pg)
+			pg.NormalizeHeight()
+			checkGolden(t, pg.RenderImage(), filepath.Join("testdata", c.name+".png"))
+		})
+	}
+}
+
+func checkGolden(t *testing.T, got image.Image, path string) {
+	t.Helper()
+	if *update {
+		if err := writePNG(path, got); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := readPNG(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if err := compareImages(got, want); err != nil {
+		failed := filepath.Join(os.TempDir(), filepath.Base(path))
+		if werr := writePNG(failed, got); werr == nil {
+			t.Errorf("%s: %v (output written to %s)", path, err, failed)
+		} else {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+}
+
+func compareImages(got, want image.Image) error {
+	if got.Bounds() != want.Bounds() {
+		return fmt.Errorf("bounds differ: got %v, want %v", got.Bounds(), want.Bounds())
+	}
+	bounds := got.Bounds()
+	bad := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r1, g1, b1, a1 := got.At(x, y).RGBA()
+			r2, g2, b2, a2 := want.At(x, y).RGBA()
+			if !closeEnough(r1, r2) || !closeEnough(g1, g2) || !closeEnough(b1, b2) || !closeEnough(a1, a2) {
+				bad++
+			}
+		}
+	}
+	allowed := int(pixelTolerance * float64(bounds.Dx()*bounds.Dy()))
+	if bad > allowed {
+		return fmt.Errorf("%d pixels differ (%d allowed)", bad, allowed)
+	}
+	return nil
+}
+
+func closeEnough(a, b uint32) bool {
+	// RGBA returns 16 bit channels
+	a, b = a>>8, b>>8
+	if a > b {
+		return a-b <= channelTolerance
+	}
+	return b-a <= channelTolerance
+}
+
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func writePNG(path string, m image.Image) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, m); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
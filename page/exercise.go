@@ -0,0 +1,370 @@
+package page
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"vll/eventlog"
+
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// Exercise is a statement to prove, along with restrictions on how it can be
+// proved
+type Exercise struct {
+	Name      string `json:"name"`
+	Statement string `json:"statement"`
+	// what the proof starts from, in Tolestra's notation, or nothing if it's empty
+	From string `json:"from,omitempty"`
+	// the proof can't use blue or red loops
+	NoExponentials bool `json:"no-exponentials,omitempty"`
+	// the most steps the proof can take, or any number if it's 0
+	MaxSteps int `json:"max-steps,omitempty"`
+}
+
+// ExerciseSet is the exercises in an exercise file
+type ExerciseSet struct {
+	Title     string     `json:"title"`
+	Exercises []Exercise `json:"exercises"`
+}
+
+// LoadExerciseSet reads an exercise file, checking that every exercise in it
+// has a name of its own, and that its statement and what it starts from can
+// be read. Whether an exercise can actually be proved isn't checked.
+func LoadExerciseSet(path string) (*ExerciseSet, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var set ExerciseSet
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("%v isn't an exercise file: %v", path, err)
+	}
+	names := make(map[string]bool)
+	for _, ex := range set.Exercises {
+		if ex.Name == "" || names[ex.Name] {
+			return nil, fmt.Errorf("%v: every exercise needs a name of its own", path)
+		}
+		names[ex.Name] = true
+		if _, err := ParseTolestra(ex.Statement); err != nil {
+			return nil, fmt.Errorf("%v: exercise %q: %v", path, ex.Name, err)
+		}
+		if _, err := ex.Start(nil); err != nil {
+			return nil, fmt.Errorf("%v: exercise %q: %v", path, ex.Name, err)
+		}
+	}
+	return &set, nil
+}
+
+// Start makes the page the exercise is proved on, in proof mode, with what the
+// proof starts from
+func (ex Exercise) Start(win *pixelgl.Window) (*Page, error) {
+	pg, err := NewProofPage(win, ex.From)
+	if err != nil {
+		return nil, err
+	}
+	pg.Name = ex.Name
+	return pg, nil
+}
+
+// Restrictions describes the restrictions on how the exercise can be proved
+func (ex Exercise) Restrictions() []string {
+	var restrictions []string
+	if ex.NoExponentials {
+		restrictions = append(restrictions, "no exponentials")
+	}
+	if ex.MaxSteps > 0 {
+		restrictions = append(restrictions, fmt.Sprintf("at most %v steps", ex.MaxSteps))
+	}
+	return restrictions
+}
+
+// Result is how a proof of an exercise was graded
+type Result struct {
+	Exercise string `json:"exercise"`
+	Proved   bool   `json:"proved"`
+	Steps    int    `json:"steps"`
+	// what's wrong with the proof, if it doesn't prove the statement
+	Problem string `json:"problem,omitempty"`
+}
+
+// Check replays a proof of the exercise, given as the statement after each
+// step, and grades it, along with the proofs of any lemmas it uses. Every
+// step has to be one that can be taken while proving, or filling in an
+// assumption or a contingency, or using one of the lemmas, although
+// statements in between which aren't, like a bubble half dragged, are
+// skipped over.
+func (ex Exercise) Check(history []*Bubble, lemmas ...*Proof) Result {
+	result := Result{Exercise: ex.Name}
+	fail := func(format string, args ...interface{}) Result {
+		result.Problem = fmt.Sprintf(format, args...)
+		return result
+	}
+	start, err := ex.Start(nil)
+	if err != nil {
+		return fail("%v", err)
+	}
+	goal, err := NewProofPage(nil, ex.Statement)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if len(history) == 0 {
+		return fail("there's no proof")
+	}
+	if !history[0].SameAs(start.Root) {
+		return fail("the proof doesn't start from %v", formulaOf(start.Root))
+	}
+
+	proved, err := checkLemmas(lemmas)
+	if err != nil {
+		return fail("%v", err)
+	}
+	c := &checker{lemmas: proved}
+
+	from := history[0]
+	skipped := -1
+	for i, statement := range history[1:] {
+		op, continued, ok := c.follows(from, statement)
+		if !ok {
+			if skipped < 0 {
+				skipped = i + 1
+			}
+			continue
+		}
+		// filling in an assumption or contingency is part of the step that started it
+		if !continued {
+			result.Steps++
+		}
+		from, skipped = statement, -1
+		if ex.NoExponentials && usesExponentials(statement) {
+			return fail("step %v (%v) uses exponentials", result.Steps, op)
+		}
+	}
+	if skipped >= 0 {
+		return fail("step %v doesn't follow from the one before it", skipped)
+	}
+	if ex.MaxSteps > 0 && result.Steps > ex.MaxSteps {
+		return fail("the proof takes %v steps, but can take at most %v", result.Steps, ex.MaxSteps)
+	}
+	if from.Tolestra() != goal.Root.Tolestra() {
+		return fail("the proof gets to %v, not %v", formulaOf(from), goal.Root.Tolestra())
+	}
+	result.Proved = true
+	return result
+}
+
+// usesExponentials returns whether there are any blue or red loops in a statement
+func usesExponentials(b *Bubble) bool {
+	found := false
+	b.Iterate(func(bub *Bubble) {
+		if bub.Kind.IsExponential() {
+			found = true
+		}
+	})
+	return found
+}
+
+// formulaOf is a statement in Tolestra's notation, with the empty statement spelled out
+func formulaOf(b *Bubble) string {
+	if str := b.Tolestra(); str != "" {
+		return str
+	}
+	return "nothing"
+}
+
+// Submission is a student's proofs of the exercises in a set, as the
+// statement after each step of each proof, along with the proofs of the
+// lemmas they use
+type Submission struct {
+	Set     string                    `json:"set"`
+	Student string                    `json:"student"`
+	Proofs  map[string][]*savedBubble `json:"proofs"`
+	Lemmas  map[string][]*savedProof  `json:"lemmas,omitempty"`
+	// where the submission is saved
+	File string `json:"-"`
+}
+
+// NewSubmission starts a student's submission for an exercise set
+func NewSubmission(set *ExerciseSet, student, path string) *Submission {
+	return &Submission{
+		Set:     set.Title,
+		Student: student,
+		Proofs:  make(map[string][]*savedBubble),
+		Lemmas:  make(map[string][]*savedProof),
+		File:    path,
+	}
+}
+
+// LoadSubmission reads a submission back in
+func LoadSubmission(path string) (*Submission, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	sub := &Submission{File: path}
+	if err := json.Unmarshal(data, sub); err != nil {
+		return nil, fmt.Errorf("%v isn't a submission: %v", path, err)
+	}
+	if sub.Proofs == nil {
+		sub.Proofs = make(map[string][]*savedBubble)
+	}
+	if sub.Lemmas == nil {
+		sub.Lemmas = make(map[string][]*savedProof)
+	}
+	return sub, nil
+}
+
+// Save writes the submission to its file
+func (sub *Submission) Save() error {
+	data, err := json.MarshalIndent(sub, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(sub.File, data, 0644); err != nil {
+		return err
+	}
+	Log.Info("submit", eventlog.F("file", sub.File), eventlog.F("student", sub.Student), eventlog.F("proofs", len(sub.Proofs)))
+	return nil
+}
+
+// Record puts the proof so far on a page into the submission, as the proof of an exercise
+func (sub *Submission) Record(exercise string, pg *Page) {
+	var history []*savedBubble
+	for _, step := range pg.History {
+		history = append(history, saveBubble(step))
+	}
+	sub.Proofs[exercise] = history
+	var lemmas []*savedProof
+	for _, lemma := range pg.Lemmas {
+		lemmas = append(lemmas, saveProof(lemma))
+	}
+	sub.Lemmas[exercise] = lemmas
+}
+
+// Proof is the submitted proof of an exercise
+func (sub *Submission) Proof(exercise string) ([]*Bubble, error) {
+	var history []*Bubble
+	for _, saved := range sub.Proofs[exercise] {
+		b, err := saved.bubble()
+		if err != nil {
+			return nil, fmt.Errorf("the proof of %q: %v", exercise, err)
+		}
+		history = append(history, b)
+	}
+	return history, nil
+}
+
+// LemmasOf is the proofs of the lemmas used in the submitted proof of an exercise
+func (sub *Submission) LemmasOf(exercise string) ([]*Proof, error) {
+	var lemmas []*Proof
+	for _, saved := range sub.Lemmas[exercise] {
+		p, err := saved.proof()
+		if err != nil {
+			return nil, fmt.Errorf("a lemma in the proof of %q: %v", exercise, err)
+		}
+		lemmas = append(lemmas, p)
+	}
+	return lemmas, nil
+}
+
+// Grade checks the submitted proof of every exercise in the set
+func (set *ExerciseSet) Grade(sub *Submission) []Result {
+	var results []Result
+	for _, ex := range set.Exercises {
+		history, err := sub.Proof(ex.Name)
+		if err != nil {
+			results = append(results, Result{Exercise: ex.Name, Problem: err.Error()})
+			continue
+		}
+		lemmas, err := sub.LemmasOf(ex.Name)
+		if err != nil {
+			results = append(results, Result{Exercise: ex.Name, Problem: err.Error()})
+			continue
+		}
+		results = append(results, ex.Check(history, lemmas...))
+	}
+	return results
+}
+
+// ExerciseSession is a student working through an exercise set, with a page
+// for each exercise
+type ExerciseSession struct {
+	Set        *ExerciseSet
+	Submission *Submission
+	// the page of each exercise, in the same order
+	Pages []*Page
+	// the last result of checking each page, and the step it was checked at
+	results map[*Page]Result
+	checked map[*Page]*Bubble
+}
+
+// OpenExercises adds a page for each exercise in the set to a workspace,
+// picking up the proofs already in the submission
+func OpenExercises(ws *Workspace, set *ExerciseSet, sub *Submission) (*ExerciseSession, error) {
+	if sub.Set != set.Title {
+		return nil, fmt.Errorf("%v is a submission for %q, not %q", sub.File, sub.Set, set.Title)
+	}
+	session := &ExerciseSession{Set: set, Submission: sub, results: make(map[*Page]Result), checked: make(map[*Page]*Bubble)}
+	for _, ex := range set.Exercises {
+		pg, err := ex.Start(ws.win)
+		if err != nil {
+			return nil, err
+		}
+		history, err := sub.Proof(ex.Name)
+		if err != nil {
+			return nil, err
+		}
+		if pg.Lemmas, err = sub.LemmasOf(ex.Name); err != nil {
+			return nil, err
+		}
+		if len(history) > 0 {
+			if !history[0].SameAs(pg.History[0]) {
+				return nil, fmt.Errorf("the proof of %q in %v doesn't start from the exercise", ex.Name, sub.File)
+			}
+			pg.History = history
+			pg.setRoot(history[len(history)-1])
+		}
+		session.Pages = append(session.Pages, pg)
+		ws.Add(pg)
+	}
+	if len(session.Pages) == 0 {
+		return nil, errors.New("there aren't any exercises")
+	}
+	return session, nil
+}
+
+// Exercise finds the exercise a page is for
+func (session *ExerciseSession) Exercise(pg *Page) (Exercise, bool) {
+	for i, exercisePage := range session.Pages {
+		if exercisePage == pg {
+			return session.Set.Exercises[i], true
+		}
+	}
+	return Exercise{}, false
+}
+
+// Result grades the proof on an exercise's page so far. It's only checked
+// again once another step is taken.
+func (session *ExerciseSession) Result(pg *Page) Result {
+	ex, _ := session.Exercise(pg)
+	if pg.Mode != ProofMode {
+		return Result{Exercise: ex.Name, Problem: fmt.Sprintf("the page is in %v mode", pg.Mode)}
+	}
+	last := pg.History[len(pg.History)-1]
+	if session.checked[pg] != last {
+		session.results[pg] = ex.Check(pg.History, pg.Lemmas...)
+		session.checked[pg] = last
+	}
+	return session.results[pg]
+}
+
+// Save records the proofs on every exercise's page, and saves the submission
+func (session *ExerciseSession) Save() error {
+	for i, pg := range session.Pages {
+		if pg.Mode == ProofMode {
+			session.Submission.Record(session.Set.Exercises[i].Name, pg)
+		}
+	}
+	return session.Submission.Save()
+}
@@ -0,0 +1,137 @@
+package page
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+const exerciseFile = `{
+	"title": "Week 1",
+	"exercises": [
+		{"name": "Excluded middle", "statement": "(A + ~A)", "no-exponentials": true, "max-steps": 2},
+		{"name": "Modus ponens", "statement": "B", "from": "(A * (B + ~A))"}
+	]
+}`
+
+func writeFile(t *testing.T, name, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	assert.NilError(t, ioutil.WriteFile(path, []byte(contents), 0644))
+	return path
+}
+
+// prove takes steps on an exercise's page
+func prove(t *testing.T, pg *Page, steps ...string) {
+	t.Helper()
+	for _, str := range steps {
+		s, err := ParseStep(str)
+		assert.NilError(t, err)
+		_, err = pg.TakeStep(s)
+		assert.NilError(t, err, "step %v", s)
+	}
+}
+
+func TestLoadExerciseSet(t *testing.T) {
+	set, err := LoadExerciseSet(writeFile(t, "week1.json", exerciseFile))
+	assert.NilError(t, err)
+	assert.Equal(t, set.Title, "Week 1")
+	assert.DeepEqual(t, set.Exercises[0].Restrictions(), []string{"no exponentials", "at most 2 steps"})
+
+	_, err = LoadExerciseSet(writeFile(t, "twice.json", `{"exercises": [{"name": "A", "statement": "A"}, {"name": "A", "statement": "B"}]}`))
+	assert.ErrorContains(t, err, "name of its own")
+	_, err = LoadExerciseSet(writeFile(t, "bad.json", `{"exercises": [{"name": "A", "statement": "A *"}]}`))
+	assert.ErrorContains(t, err, `exercise "A"`)
+}
+
+func TestCheckExercise(t *testing.T) {
+	set, err := LoadExerciseSet(writeFile(t, "week1.json", exerciseFile))
+	assert.NilError(t, err)
+	middle, ponens := set.Exercises[0], set.Exercises[1]
+
+	pg, err := middle.Start(nil)
+	assert.NilError(t, err)
+	assert.Equal(t, middle.Check(pg.History).Problem, "the proof gets to 1, not (A + ~A)")
+	prove(t, pg, "loop [0]", "assume [0 0] A")
+	assert.DeepEqual(t, middle.Check(pg.History), Result{Exercise: "Excluded middle", Proved: true, Steps: 2})
+
+	// restrictions are kept to
+	long := middle
+	long.MaxSteps = 1
+	assert.Equal(t, long.Check(pg.History).Problem, "the proof takes 2 steps, but can take at most 1")
+	pg, _ = middle.Start(nil)
+	prove(t, pg, "why-not [0]")
+	assert.Equal(t, middle.Check(pg.History).Problem, "step 1 (why-not) uses exponentials")
+
+	// every step has to follow from the one before it
+	pg, _ = ponens.Start(nil)
+	skipped := append([]*Bubble{pg.History[0]}, pg.History[0].Children[0].Children[0])
+	assert.Equal(t, ponens.Check(skipped).Problem, "step 1 doesn't follow from the one before it")
+	assert.Equal(t, ponens.Check(nil).Problem, "there's no proof")
+	prove(t, pg, "move [0 0 0] -> [0 0 1 1]")
+	assert.Assert(t, ponens.Check(pg.History).Proved)
+	assert.Equal(t, middle.Check(pg.History).Problem, "the proof doesn't start from 1")
+}
+
+func TestCheckExerciseEditorSteps(t *testing.T) {
+	// contingencies and assumptions of more than a variable are graded like
+	// any other step, however many statements it takes to fill them in
+	for _, pg := range []*Page{contingency(t), compoundAssumption(t)} {
+		ex := Exercise{Name: "Editor", Statement: pg.Root.Tolestra(), MaxSteps: 2}
+		assert.DeepEqual(t, ex.Check(pg.History), Result{Exercise: "Editor", Proved: true, Steps: 2})
+	}
+
+	// and so are lemmas, given their proofs
+	ex := Exercise{Name: "Lemma", Statement: "((A + ~A) * B)", From: "B"}
+	pg, err := ex.Start(nil)
+	assert.NilError(t, err)
+	pg.Execute(func() { assert.NilError(t, pg.UseLemma(excludedMiddle(t), pg.Root.Children[0], 0, 0)) })
+	assert.Equal(t, ex.Check(pg.History).Problem, "step 1 doesn't follow from the one before it")
+	assert.Assert(t, ex.Check(pg.History, pg.Lemmas...).Proved)
+
+	// which are submitted along with the proof
+	set := &ExerciseSet{Title: "Lemmas", Exercises: []Exercise{ex}}
+	sub := NewSubmission(set, "bob", filepath.Join(t.TempDir(), "lemmas-bob.json"))
+	sub.Record(ex.Name, pg)
+	assert.NilError(t, sub.Save())
+	sub, err = LoadSubmission(sub.File)
+	assert.NilError(t, err)
+	assert.Assert(t, set.Grade(sub)[0].Proved)
+}
+
+func TestSubmission(t *testing.T) {
+	set, err := LoadExerciseSet(writeFile(t, "week1.json", exerciseFile))
+	assert.NilError(t, err)
+	path := filepath.Join(t.TempDir(), "week1-alice.json")
+
+	ws, err := NewWorkspace(nil)
+	assert.NilError(t, err)
+	session, err := OpenExercises(ws, set, NewSubmission(set, "alice", path))
+	assert.NilError(t, err)
+	assert.Equal(t, len(ws.Pages), 3)
+	prove(t, session.Pages[1], "move [0 0 0] -> [0 0 1 1]")
+	assert.Assert(t, session.Result(session.Pages[1]).Proved)
+	assert.NilError(t, session.Save())
+
+	// grading replays the proofs
+	sub, err := LoadSubmission(path)
+	assert.NilError(t, err)
+	assert.Equal(t, sub.Student, "alice")
+	results := set.Grade(sub)
+	assert.Equal(t, len(results), 2)
+	assert.Assert(t, !results[0].Proved)
+	assert.Assert(t, results[1].Proved)
+
+	// and the proofs are picked back up
+	ws, _ = NewWorkspace(nil)
+	session, err = OpenExercises(ws, set, sub)
+	assert.NilError(t, err)
+	assert.Equal(t, len(session.Pages[1].History), 2)
+	assert.Equal(t, session.Pages[1].Root.Tolestra(), "B")
+
+	sub.Set = "Week 2"
+	_, err = OpenExercises(ws, set, sub)
+	assert.ErrorContains(t, err, `for "Week 2", not "Week 1"`)
+}
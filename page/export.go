@@ -0,0 +1,277 @@
+package page
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+	"vll/eventlog"
+
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/math/fixed"
+)
+
+// how much room is left around the proof when it's fitted into an animation
+const exportMargin = 160
+
+// Animation is how a proof's replay is exported
+type Animation struct {
+	// the size of each frame, in pixels
+	Width, Height int
+	// how many frames there are a second
+	FPS int
+	// how many steps are played a second
+	Speed float64
+	// whether the statement and the step being taken are shown beside the proof
+	Sidebar bool
+	// the colors the proof is drawn in, or the page's own if it's nil
+	Theme *Theme
+}
+
+// DefaultAnimation is the size and speed animations are exported at unless
+// they're told otherwise
+var DefaultAnimation = Animation{Width: width, Height: height, FPS: 12, Speed: 1, Sidebar: true}
+
+func (a Animation) check() error {
+	if a.Width <= 0 || a.Height <= 0 {
+		return fmt.Errorf("an animation can't be %vx%v", a.Width, a.Height)
+	}
+	if a.Sidebar && a.Width <= sidebar {
+		return fmt.Errorf("an animation with a sidebar has to be wider than %v", sidebar)
+	}
+	if a.FPS <= 0 || a.FPS > 100 {
+		return fmt.Errorf("an animation can't have %v frames a second", a.FPS)
+	}
+	if a.Speed <= 0 {
+		return fmt.Errorf("an animation can't play %v steps a second", a.Speed)
+	}
+	return nil
+}
+
+// Render plays the proof on a page back from the beginning without a window,
+// and hands each frame to draw in turn. The camera is fitted around every
+// statement of the proof, so it stays still throughout.
+func (a Animation) Render(pg *Page, draw func(frame *image.RGBA) error) error {
+	if err := a.check(); err != nil {
+		return err
+	}
+	r, err := NewReplay(pg)
+	if err != nil {
+		return err
+	}
+	r.Page.win = nil
+	r.Page.Name = pg.Title()
+	r.Page.Camera = a.camera(r.History)
+	if a.Theme != nil {
+		r.Page.Theme = a.Theme
+	}
+	r.Speed = a.Speed
+	r.Playing = true
+
+	frame := 1 / float64(a.FPS)
+	for {
+		if err := draw(a.frame(r)); err != nil {
+			return err
+		}
+		if r.Finished() {
+			return nil
+		}
+		r.Advance(time.Duration(frame * float64(time.Second)))
+	}
+}
+
+// camera fits every statement of a proof into the part of the frame which
+// isn't the sidebar. Without a sidebar, the frame is rendered that much wider
+// and cropped afterwards.
+func (a Animation) camera(history []*Bubble) *Camera {
+	c := NewCamera(a.Width, a.Height)
+	if !a.Sidebar {
+		c.Width += sidebar
+	}
+	minX, minY := math.Inf(1), math.Inf(1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, statement := range history {
+		statement.Iterate(func(b *Bubble) {
+			minX, maxX = math.Min(minX, float64(b.X)), math.Max(maxX, float64(b.X))
+			minY, maxY = math.Min(minY, float64(b.Y)), math.Max(maxY, float64(b.Y))
+		})
+	}
+	minX, minY = minX-exportMargin, minY-exportMargin
+	maxX, maxY = maxX+exportMargin, maxY+exportMargin
+	w, h := float64(a.Width), float64(a.Height)
+	if a.Sidebar {
+		w -= sidebar
+	}
+	c.Zoom = math.Max(minZoom, math.Min(maxZoom, math.Min(w/(maxX-minX), h/(maxY-minY))))
+	// center the proof in the frame
+	c.X = (minX+maxX)/2 - (w/2+sidebar)/c.Zoom
+	c.Y = (minY+maxY)/2 - h/2/c.Zoom
+	return c
+}
+
+// frame renders the replay as it is, with its labels and maybe the sidebar
+func (a Animation) frame(r *Replay) *image.RGBA {
+	m := r.Page.RenderImage()
+	r.Page.labelImage(m)
+	if a.Sidebar {
+		r.drawSidebar(m)
+		return m
+	}
+	cropped := image.NewRGBA(image.Rect(0, 0, a.Width, a.Height))
+	xdraw.Draw(cropped, cropped.Bounds(), m, image.Pt(sidebar, 0), xdraw.Src)
+	return cropped
+}
+
+// labelImage writes the variables of the bubbles onto an image of the page,
+// the same size and in the same place that Label draws them on the window
+func (pg *Page) labelImage(m *image.RGBA) {
+	pg.Root.Iterate(func(b *Bubble) {
+		if b.Variable == "" {
+			return
+		}
+		sx, sy := pg.Camera.ToScreen(b.X, b.Y)
+		if sx < sidebar {
+			return
+		}
+		zoom := pg.Camera.Zoom
+		if scale, ok := pg.scale[b]; ok {
+			zoom *= scale
+		}
+		x := sx + 3*zoom - 14*zoom*float64(len(b.Variable))
+		baseline := sy + 15*zoom
+		clr := pg.Theme.Label(b.Kind)
+
+		// write the label at the size of the font, and then blow it up
+		small := image.NewRGBA(image.Rect(0, 0, face.Advance*len(b.Variable), face.Height))
+		d := font.Drawer{Dst: small, Src: image.NewUniform(clr), Face: face, Dot: fixed.P(0, face.Ascent)}
+		d.DrawString(b.Variable)
+		s := 4 * zoom
+		dst := image.Rect(
+			int(x), int(baseline-float64(face.Ascent)*s),
+			int(x+float64(small.Bounds().Dx())*s), int(baseline+float64(face.Descent)*s),
+		)
+		xdraw.NearestNeighbor.Scale(m, dst, small, small.Bounds(), xdraw.Over, nil)
+	})
+}
+
+// drawSidebar writes what's being proved, where the proof has got to, and the
+// step being taken, down the side of a frame
+func (r *Replay) drawSidebar(m *image.RGBA) {
+	d := font.Drawer{Dst: m, Src: image.NewUniform(r.Page.Theme.Text), Face: face}
+	y := 20
+	line := func(str string) {
+		d.Dot = fixed.P(10, y)
+		d.DrawString(str)
+		y += face.Height + 3
+	}
+	// the sidebar fits this many characters across
+	wrap := (sidebar - 20) / face.Advance
+	paragraph := func(str string) {
+		runes := []rune(str)
+		for len(runes) > wrap {
+			line(string(runes[:wrap]))
+			runes = runes[wrap:]
+		}
+		line(string(runes))
+		y += face.Height
+	}
+
+	line(r.Page.Title())
+	y += face.Height
+	line("Proving")
+	paragraph(prettyOf(r.History[0]))
+	line(fmt.Sprintf("Step %v of %v", r.Step, r.Steps()))
+	if !r.Finished() {
+		if s, ok := StepBetween(r.History[r.Step], r.History[r.Step+1]); ok {
+			line("Next: " + s.Op)
+		}
+	}
+	y += face.Height
+	line("Goal")
+	paragraph(prettyOf(r.History[r.Step]))
+}
+
+// prettyOf is a statement written with Pretty, or nothing if it's empty
+func prettyOf(b *Bubble) string {
+	if str := b.Pretty(); str != "" {
+		return str
+	}
+	return "nothing"
+}
+
+// exportPalette is the colors frames are drawn with, followed by as many
+// others as fit, for whatever a frame is shaded with besides
+func exportPalette(theme *Theme) color.Palette {
+	p := theme.Palette()
+	for _, c := range palette.WebSafe {
+		if len(p) == 256 {
+			break
+		}
+		p = append(p, c)
+	}
+	return p
+}
+
+// WriteGIF exports the proof on a page as an animated GIF, which lingers on
+// the last step before it loops
+func (a Animation) WriteGIF(pg *Page, w io.Writer) error {
+	anim := &gif.GIF{}
+	theme := a.Theme
+	if theme == nil {
+		theme = pg.Theme
+	}
+	p := exportPalette(theme)
+	delay := 100 / a.FPS
+	err := a.Render(pg, func(frame *image.RGBA) error {
+		paletted := image.NewPaletted(frame.Bounds(), p)
+		xdraw.Draw(paletted, paletted.Bounds(), frame, image.Point{}, xdraw.Src)
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, delay)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	anim.Delay[len(anim.Delay)-1] += 200
+	if err := gif.EncodeAll(w, anim); err != nil {
+		return err
+	}
+	Log.Info("export", eventlog.F("format", "gif"), eventlog.F("frames", len(anim.Image)))
+	return nil
+}
+
+// WritePNGs exports the proof on a page as a PNG file for each frame, numbered
+// in order in the given directory, and returns the files it wrote
+func (a Animation) WritePNGs(pg *Page, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	name := fileName(pg.Title(), "frame")
+	var files []string
+	err := a.Render(pg, func(frame *image.RGBA) error {
+		path := filepath.Join(dir, fmt.Sprintf("%v-%04d.png", name, len(files)))
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := png.Encode(f, frame); err != nil {
+			return err
+		}
+		files = append(files, path)
+		return f.Close()
+	})
+	if err != nil {
+		return files, err
+	}
+	Log.Info("export", eventlog.F("format", "png"), eventlog.F("frames", len(files)), eventlog.F("dir", dir))
+	return files, nil
+}
@@ -0,0 +1,53 @@
+package page
+
+import (
+	"bytes"
+	"image/gif"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestExportGIF(t *testing.T) {
+	pg, err := NewProofPage(nil, "(A * (B + ~A))")
+	assert.NilError(t, err)
+	pg.Name = "Annihilate"
+	prove(t, pg, "move [0 0 0] -> [0 0 1 1]")
+
+	a := Animation{Width: 400, Height: 300, FPS: 4, Speed: 2, Sidebar: true}
+	var buf bytes.Buffer
+	assert.NilError(t, a.WriteGIF(pg, &buf))
+	anim, err := gif.DecodeAll(&buf)
+	assert.NilError(t, err)
+	// the first statement, half a second of the step, and the last statement
+	assert.Equal(t, len(anim.Image), 3)
+	assert.Equal(t, anim.Config.Width, 400)
+	assert.Equal(t, anim.Config.Height, 300)
+	assert.Assert(t, anim.Delay[2] > anim.Delay[0])
+
+	// the bubbles of the proof are drawn in the middle, and the sidebar is left black
+	first := anim.Image[0]
+	assert.Assert(t, first.At(sidebar+(400-sidebar)/2, 150) != first.At(5, 295))
+	_, _, _, alpha := first.At(5, 295).RGBA()
+	assert.Equal(t, alpha, uint32(0xffff))
+
+	assert.ErrorContains(t, Animation{Width: 100, Height: 100, FPS: 4, Speed: 1, Sidebar: true}.WriteGIF(pg, &buf), "wider")
+}
+
+func TestExportPNGs(t *testing.T) {
+	pg, err := NewProofPage(nil, "A")
+	assert.NilError(t, err)
+	prove(t, pg, "loop [0 0]", "loop [0 0 0]")
+
+	a := Animation{Width: 200, Height: 150, FPS: 2, Speed: 1}
+	dir := t.TempDir()
+	files, err := a.WritePNGs(pg, dir)
+	assert.NilError(t, err)
+	assert.Equal(t, len(files), 5)
+	assert.Equal(t, files[0], filepath.Join(dir, "frame-0000.png"))
+	frame, err := readPNG(files[4])
+	assert.NilError(t, err)
+	assert.Equal(t, frame.Bounds().Dx(), 200)
+	assert.Equal(t, frame.Bounds().Dy(), 150)
+}
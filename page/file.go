@@ -0,0 +1,220 @@
+package page
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"vll/eventlog"
+
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// savedBubble is how a bubble is written to a file. Unlike Tolestra's
+// notation, it keeps units, redundant loops and where everything is.
+type savedBubble struct {
+	ID       ID             `json:"id,omitempty"`
+	Kind     string         `json:"kind"`
+	Variable string         `json:"variable,omitempty"`
+	X        int            `json:"x"`
+	Y        int            `json:"y"`
+	Children []*savedBubble `json:"children,omitempty"`
+}
+
+// savedPage is the contents of a file: the statement, and the proof of it so far
+type savedPage struct {
+	Mode      string         `json:"mode"`
+	Statement *savedBubble   `json:"statement"`
+	Theorem   *savedBubble   `json:"theorem,omitempty"`
+	History   []*savedBubble `json:"history,omitempty"`
+	Lemmas    []*savedProof  `json:"lemmas,omitempty"`
+}
+
+// savedProof is the proof of a lemma used on a saved page
+type savedProof struct {
+	Theorem *savedBubble   `json:"theorem"`
+	History []*savedBubble `json:"history"`
+}
+
+func saveProof(p *Proof) *savedProof {
+	saved := &savedProof{Theorem: saveBubble(p.Theorem)}
+	for _, step := range p.History {
+		saved.History = append(saved.History, saveBubble(step))
+	}
+	return saved
+}
+
+func (saved *savedProof) proof() (*Proof, error) {
+	if saved.Theorem == nil {
+		return nil, errors.New("a lemma doesn't have its theorem")
+	}
+	theorem, err := saved.Theorem.bubble()
+	if err != nil {
+		return nil, err
+	}
+	p := &Proof{Theorem: theorem}
+	for _, step := range saved.History {
+		b, err := step.bubble()
+		if err != nil {
+			return nil, err
+		}
+		p.History = append(p.History, b)
+	}
+	return p, nil
+}
+
+func saveBubble(b *Bubble) *savedBubble {
+	saved := &savedBubble{ID: b.ID, Kind: b.Kind.String(), Variable: b.Variable, X: b.X, Y: b.Y}
+	for _, child := range b.Children {
+		saved.Children = append(saved.Children, saveBubble(child))
+	}
+	return saved
+}
+
+func (saved *savedBubble) bubble() (*Bubble, error) {
+	kind, ok := kindNamed(saved.Kind)
+	if !ok {
+		return nil, fmt.Errorf("unknown kind of bubble %q", saved.Kind)
+	}
+	b := newBubble(saved.X, saved.Y, saved.Variable, kind)
+	// files written before bubbles were saved with their IDs get new ones
+	if saved.ID != 0 {
+		b.ID = saved.ID
+		useID(saved.ID)
+	}
+	for _, child := range saved.Children {
+		c, err := child.bubble()
+		if err != nil {
+			return nil, err
+		}
+		b.Insert(c)
+	}
+	return b, nil
+}
+
+// Title is what the page is called in the list of pages
+func (pg *Page) Title() string {
+	if pg.File != "" {
+		return strings.TrimSuffix(filepath.Base(pg.File), FileExtension)
+	}
+	return pg.Name
+}
+
+// FileExtension is the extension of files pages are saved in
+const FileExtension = ".vll"
+
+// fileName turns a name into one that can be used for a file anywhere: lower
+// case letters and digits, with a dash for everything else in between. A name
+// with nothing left is called fallback instead.
+func fileName(name, fallback string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			if dash && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	if b.Len() == 0 {
+		return fallback
+	}
+	return b.String()
+}
+
+// Save writes the statement and the proof of it so far to the page's file. A
+// page without a file gets one named after it, in the working directory.
+// Assumptions and contingencies being edited are saved as they are, but the
+// page is loaded back in proof mode.
+func (pg *Page) Save() error {
+	if pg.File == "" {
+		pg.File = fileName(pg.Name, "page") + FileExtension
+	}
+	saved := savedPage{Mode: CreateMode.String(), Statement: saveBubble(pg.Root)}
+	if pg.Mode != CreateMode {
+		saved.Mode = ProofMode.String()
+		proof := saveProof(&Proof{Theorem: pg.Theorem, History: pg.History})
+		saved.Theorem, saved.History = proof.Theorem, proof.History
+		for _, lemma := range pg.Lemmas {
+			saved.Lemmas = append(saved.Lemmas, saveProof(lemma))
+		}
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(pg.File, data, 0644); err != nil {
+		return err
+	}
+	Log.Info("save", eventlog.F("file", pg.File))
+	return nil
+}
+
+// LoadPage reads a page saved in a file, which stays associated with it. If
+// the file doesn't exist yet, the page starts out empty, and is saved there.
+// The statement has to be a valid tree, and a proof is checked step by step
+// as it's loaded, along with the proofs of the lemmas it uses, so that a file
+// can't claim to prove what it doesn't.
+func LoadPage(win *pixelgl.Window, path string) (*Page, error) {
+	pg := NewPage(win)
+	pg.File = path
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return pg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var saved savedPage
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("%v isn't a saved page: %v", path, err)
+	}
+	if saved.Statement == nil {
+		return nil, fmt.Errorf("%v doesn't have a statement", path)
+	}
+	root, err := saved.Statement.bubble()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	pg.setRoot(root)
+	if err := pg.Validate(); err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+
+	switch saved.Mode {
+	case CreateMode.String():
+	case ProofMode.String():
+		if saved.Theorem == nil || len(saved.History) == 0 {
+			return nil, fmt.Errorf("%v doesn't have the proof it's in the middle of", path)
+		}
+		proof, err := (&savedProof{Theorem: saved.Theorem, History: saved.History}).proof()
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", path, err)
+		}
+		pg.Theorem, pg.History = proof.Theorem, proof.History
+		for _, lemma := range saved.Lemmas {
+			p, err := lemma.proof()
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", path, err)
+			}
+			pg.Lemmas = append(pg.Lemmas, p)
+		}
+		pg.Mode = ProofMode
+		// the proof has to hold up, since what it proves can be used as a lemma
+		if err := pg.CheckProof(); err != nil {
+			return nil, fmt.Errorf("%v: %v", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%v is in an unknown mode %q", path, saved.Mode)
+	}
+	Log.Info("load", eventlog.F("file", path), eventlog.F("mode", pg.Mode))
+	return pg, nil
+}
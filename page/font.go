@@ -0,0 +1,101 @@
+package page
+
+import (
+	"image"
+	"image/draw"
+
+	"golang.org/x/image/font/basicfont"
+)
+
+// symbols are the runes Pretty writes which basicfont has no glyphs for, in
+// increasing order, drawn to match its 6×13 glyphs
+var symbols = []struct {
+	r     rune
+	glyph [13]string
+}{
+	{'¬', [13]string{
+		"", "", "", "", "",
+		"#####",
+		"    #",
+		"    #",
+	}},
+	{'⅋', [13]string{
+		"", "",
+		"# ### ",
+		" #   #",
+		"# #  #",
+		"#  ## ",
+		"   ## ",
+		"  #  #",
+		"  #  #",
+		"   ## ",
+	}},
+	{'⊗', [13]string{
+		"", "", "",
+		" #### ",
+		"##  ##",
+		"# ## #",
+		"# ## #",
+		"##  ##",
+		" #### ",
+	}},
+	{'⊥', [13]string{
+		"",
+		"",
+		"  #",
+		"  #",
+		"  #",
+		"  #",
+		"  #",
+		"  #",
+		"  #",
+		"#####",
+	}},
+}
+
+// symbolRunes are the runes of the symbols, for the atlas pages are drawn with
+func symbolRunes() []rune {
+	var runes []rune
+	for _, s := range symbols {
+		runes = append(runes, s.r)
+	}
+	return runes
+}
+
+// face is basicfont's 7×13 face with glyphs for the symbols added, which is
+// what pages and their exports are written in
+var face = withSymbols(basicfont.Face7x13)
+
+func withSymbols(base *basicfont.Face) *basicfont.Face {
+	old := base.Mask.Bounds()
+	glyphHeight := base.Ascent + base.Descent
+	mask := image.NewAlpha(image.Rect(0, 0, old.Dx(), old.Dy()+glyphHeight*len(symbols)))
+	draw.Draw(mask, old, base.Mask, old.Min, draw.Src)
+
+	f := *base
+	f.Mask = mask
+	f.Ranges = nil
+	offset := old.Dy() / glyphHeight
+	added := false
+	for _, rng := range base.Ranges {
+		// the symbols go before the replacement character, to keep the ranges in order
+		if !added && rng.Low > symbols[len(symbols)-1].r {
+			for i, s := range symbols {
+				f.Ranges = append(f.Ranges, basicfont.Range{Low: s.r, High: s.r + 1, Offset: offset + i})
+			}
+			added = true
+		}
+		f.Ranges = append(f.Ranges, rng)
+	}
+	for i, s := range symbols {
+		top := (offset + i) * glyphHeight
+		for y, row := range s.glyph {
+			for x, c := range row {
+				if c == '#' {
+					mask.Pix[mask.PixOffset(x, top+y)] = 0xff
+				}
+			}
+		}
+	}
+	return &f
+}
@@ -0,0 +1,35 @@
+package page
+
+import (
+	"bytes"
+	"image"
+	"testing"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"gotest.tools/assert"
+)
+
+func TestFaceSymbols(t *testing.T) {
+	draw := func(f font.Face, r rune) []byte {
+		m := image.NewAlpha(image.Rect(0, 0, face.Advance, face.Height))
+		d := font.Drawer{Dst: m, Src: image.Opaque, Face: f, Dot: fixed.P(0, face.Ascent)}
+		d.DrawString(string(r))
+		return m.Pix
+	}
+	glyph := func(r rune) []byte { return draw(face, r) }
+	// every symbol has a glyph of its own, rather than the replacement character
+	drawn := [][]byte{glyph('�'), glyph('A')}
+	for _, r := range symbolRunes() {
+		g := glyph(r)
+		for _, other := range drawn {
+			assert.Assert(t, !bytes.Equal(g, other), "%c", r)
+		}
+		drawn = append(drawn, g)
+	}
+	// and adding them doesn't move the glyphs that were already there
+	for _, r := range "A~?�" {
+		assert.Assert(t, bytes.Equal(glyph(r), draw(basicfont.Face7x13, r)), "%c", r)
+	}
+}
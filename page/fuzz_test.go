@@ -0,0 +1,32 @@
+//go:build go1.18
+// +build go1.18
+
+package page
+
+import "testing"
+
+func FuzzParseTolestra(f *testing.F) {
+	for _, seed := range []string{"A", "(A * B)", "(A + ~A)", "!(A * B), ?~C", "A -o B", "¬A ⊗ B ⅋ ⊥", "~(A * !1)", "(("} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		bubbles, err := ParseTolestra(s)
+		if err != nil {
+			return
+		}
+		for _, b := range bubbles {
+			if err := b.Validate(); err != nil {
+				t.Fatalf("%q parsed into an invalid tree: %v", s, err)
+			}
+			// writing a parsed formula out and reading it back doesn't change it
+			written := b.Tolestra()
+			again, err := ParseTolestra(written)
+			if err != nil {
+				t.Fatalf("%q was written as %q, which can't be read back: %v", s, written, err)
+			}
+			if len(again) != 1 || again[0].Tolestra() != written {
+				t.Fatalf("%q was written as %q, which reads back differently", s, written)
+			}
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package page
+
+// reindex rebuilds the index of bubbles by ID
+func (pg *Page) reindex() {
+	pg.index = make(map[ID]*Bubble)
+	pg.Root.Iterate(func(b *Bubble) {
+		pg.index[b.ID] = b
+	})
+}
+
+// Lookup finds the bubble in the statement with the given ID
+func (pg *Page) Lookup(id ID) (*Bubble, bool) {
+	b, ok := pg.index[id]
+	if !ok || !pg.Root.IsAbove(b) {
+		// the statement changed without going through Execute
+		pg.reindex()
+		b, ok = pg.index[id]
+	}
+	return b, ok
+}
+
+// LookupAll finds the bubbles with the given IDs, skipping any which aren't in the statement
+func (pg *Page) LookupAll(ids ...ID) []*Bubble {
+	bubbles := make([]*Bubble, 0, len(ids))
+	for _, id := range ids {
+		if b, ok := pg.Lookup(id); ok {
+			bubbles = append(bubbles, b)
+		}
+	}
+	return bubbles
+}
+
+// IDs lists the IDs of some bubbles
+func IDs(bubbles ...*Bubble) []ID {
+	ids := make([]ID, len(bubbles))
+	for i, b := range bubbles {
+		ids[i] = b.ID
+	}
+	return ids
+}
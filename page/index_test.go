@@ -0,0 +1,69 @@
+package page
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestIDs(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	a := add(w, 0, 0, "A", WHITE)
+	assert.Assert(t, a.ID != w.ID)
+	assert.Assert(t, a.Copy().ID != a.ID)
+	assert.Equal(t, a.ID.String()[0], byte('#'))
+
+	// bubbles added outside Execute are still found
+	found, ok := pg.Lookup(a.ID)
+	assert.Assert(t, ok)
+	assert.Equal(t, found, a)
+
+	pg.Execute(func() { pg.Loop(BLACK, a) })
+	loop := a.Parent
+	assert.DeepEqual(t, pg.LookupAll(loop.ID, a.ID), []*Bubble{loop, a})
+
+	// detached bubbles aren't found
+	pg.Execute(func() { pg.Delete(loop) })
+	_, ok = pg.Lookup(a.ID)
+	assert.Assert(t, !ok)
+	assert.DeepEqual(t, IDs(w, pg.Root), []ID{w.ID, pg.Root.ID})
+}
+
+func TestIDsAreKept(t *testing.T) {
+	every := func(root *Bubble) []ID {
+		var ids []ID
+		root.Iterate(func(b *Bubble) { ids = append(ids, b.ID) })
+		return ids
+	}
+	pg, err := NewProofPage(nil, "(A * (B + ~A))")
+	assert.NilError(t, err)
+	ids := every(pg.Root)
+	assert.DeepEqual(t, every(pg.Theorem), ids)
+	assert.DeepEqual(t, every(pg.History[0]), ids)
+
+	// undoing a step, or rewinding to before it, gives back the same bubbles
+	undo, err := pg.TakeStep(Step{Op: "move", Subject: []int{0, 0, 0}, Target: []int{0, 0, 1, 1}})
+	assert.NilError(t, err)
+	assert.NilError(t, pg.undo(undo))
+	assert.DeepEqual(t, every(pg.Root), ids)
+	_, err = pg.TakeStep(Step{Op: "move", Subject: []int{0, 0, 0}, Target: []int{0, 0, 1, 1}})
+	assert.NilError(t, err)
+	assert.NilError(t, pg.Rewind(0))
+	assert.DeepEqual(t, every(pg.Root), ids)
+
+	// and so does saving and loading them
+	pg.File = filepath.Join(t.TempDir(), "ids.vll")
+	assert.NilError(t, pg.Save())
+	loaded, err := LoadPage(nil, pg.File)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, every(loaded.Root), ids)
+	assert.DeepEqual(t, every(loaded.History[0]), ids)
+	for _, id := range ids {
+		assert.Assert(t, nextID() > id)
+	}
+
+	// but copies are new bubbles
+	assert.Assert(t, pg.Root.Copy().ID != pg.Root.ID)
+}
@@ -0,0 +1,105 @@
+package page
+
+// Kind is what a bubble means in linear logic: the connective it joins what's
+// inside it with, and its polarity. How each kind is drawn is up to the Theme.
+type Kind int
+
+const (
+	// the zero Kind isn't any kind at all, so a bubble which was never given
+	// one is caught by Validate
+	_ Kind = iota
+	// White bubbles are tensors, and variables in them are positive
+	WHITE
+	// Black bubbles are pars, and variables in them are negated
+	BLACK
+	// Blue loops are of-course
+	BLUE
+	// Red loops are why-not
+	RED
+	// the background is the root of every page, and behaves like White
+	BACKGROUND
+)
+
+// Kinds is every kind of bubble
+var Kinds = []Kind{WHITE, BLACK, BLUE, RED, BACKGROUND}
+
+// String is the name of the kind, as saved in files
+func (k Kind) String() string {
+	switch k {
+	case WHITE:
+		return "White"
+	case BLACK:
+		return "Black"
+	case BLUE:
+		return "Blue"
+	case RED:
+		return "Red"
+	case BACKGROUND:
+		return "Root"
+	default:
+		return "Unknown"
+	}
+}
+
+// kindNamed is the kind with the given name, as given by String
+func kindNamed(name string) (Kind, bool) {
+	for _, k := range Kinds {
+		if k.String() == name {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// Polarity is the multiplicative kind a kind behaves like: Black for Black
+// and Red, and White for everything else
+func (k Kind) Polarity() Kind {
+	if k == BLACK || k == RED {
+		return BLACK
+	}
+	return WHITE
+}
+
+// Dual is the kind a bubble becomes when it's negated. The background has no
+// dual of its own, and goes to Black like White does.
+func (k Kind) Dual() Kind {
+	switch k {
+	case WHITE, BACKGROUND:
+		return BLACK
+	case BLACK:
+		return WHITE
+	case BLUE:
+		return RED
+	case RED:
+		return BLUE
+	default:
+		return k
+	}
+}
+
+// Connective is the symbol of the connective the kind stands for, or nothing
+// for the background
+func (k Kind) Connective() string {
+	switch k {
+	case WHITE:
+		return "⊗"
+	case BLACK:
+		return "⅋"
+	case BLUE:
+		return "!"
+	case RED:
+		return "?"
+	default:
+		return ""
+	}
+}
+
+// IsMultiplicative returns whether the kind is White or Black
+func (k Kind) IsMultiplicative() bool {
+	return k == WHITE || k == BLACK
+}
+
+// IsExponential returns whether the kind is a Blue or Red loop
+func (k Kind) IsExponential() bool {
+	return k == BLUE || k == RED
+}
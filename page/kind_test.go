@@ -0,0 +1,33 @@
+package page
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestKinds(t *testing.T) {
+	for _, k := range Kinds {
+		named, ok := kindNamed(k.String())
+		assert.Assert(t, ok)
+		assert.Equal(t, named, k)
+		assert.Equal(t, k.Polarity().Polarity(), k.Polarity())
+		if k != BACKGROUND {
+			assert.Equal(t, k.Dual().Dual(), k, "%v", k)
+			assert.Equal(t, k.Dual().Polarity(), k.Polarity().Dual(), "%v", k)
+			assert.Assert(t, k.Connective() != "")
+		}
+		assert.Assert(t, k.IsMultiplicative() != k.IsExponential() || k == BACKGROUND)
+	}
+	assert.Equal(t, BLUE.Polarity(), WHITE)
+	assert.Equal(t, RED.Polarity(), BLACK)
+	assert.Equal(t, BLUE.Dual(), RED)
+	assert.Equal(t, WHITE.Connective(), "⊗")
+	assert.Equal(t, BLACK.Connective(), "⅋")
+
+	// a bubble that was never given a kind isn't any of them
+	var unknown Kind
+	assert.Equal(t, unknown.String(), "Unknown")
+	_, ok := kindNamed("Unknown")
+	assert.Assert(t, !ok)
+}
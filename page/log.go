@@ -0,0 +1,28 @@
+package page
+
+import (
+	"fmt"
+	"vll/eventlog"
+)
+
+// how many events are remembered
+const logSize = 500
+
+// Log records every change made to bubbles
+var Log = eventlog.New(logSize)
+
+// describe is how a bubble is written in the log
+func describe(b *Bubble) string {
+	if b == nil {
+		return "none"
+	}
+	if b.Variable != "" {
+		return fmt.Sprintf("%v%v(%q)", b.Kind, b.ID, b.Variable)
+	}
+	return b.Kind.String() + b.ID.String()
+}
+
+// Field is a log field describing a bubble
+func Field(key string, b *Bubble) eventlog.Field {
+	return eventlog.F(key, describe(b))
+}
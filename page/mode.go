@@ -0,0 +1,198 @@
+package page
+
+import (
+	"fmt"
+	"vll/eventlog"
+)
+
+// Mode is the state of the editor, which determines what the user is allowed to do
+type Mode int
+
+const (
+	// CreateMode lets the statement be edited freely
+	CreateMode Mode = iota
+	// ProofMode only allows logically valid manipulations of the statement
+	ProofMode
+	// AssumptionMode edits both bubbles of an assumption pair at once
+	AssumptionMode
+	// ContingencyMode lets the inside of a new red loop be edited freely
+	ContingencyMode
+)
+
+func (m Mode) String() string {
+	switch m {
+	case CreateMode:
+		return "Create"
+	case ProofMode:
+		return "Proof"
+	case AssumptionMode:
+		return "Assumption"
+	case ContingencyMode:
+		return "Contingency"
+	default:
+		return "Unknown"
+	}
+}
+
+// Modes lists every mode
+var Modes = []Mode{CreateMode, ProofMode, AssumptionMode, ContingencyMode}
+
+// the modes which can be reached from each mode
+var transitions = map[Mode][]Mode{
+	CreateMode:      {ProofMode},
+	ProofMode:       {AssumptionMode, ContingencyMode, CreateMode},
+	AssumptionMode:  {ProofMode},
+	ContingencyMode: {ProofMode},
+}
+
+// CanTransition returns whether the editor is allowed to go from one mode to another
+func (m Mode) CanTransition(to Mode) bool {
+	if m == to {
+		return true
+	}
+	for _, next := range transitions[m] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionError is returned when trying to switch between modes that aren't connected
+type TransitionError struct {
+	From Mode
+	To   Mode
+}
+
+func (e *TransitionError) Error() string {
+	return fmt.Sprintf("can't go from %v mode to %v mode", e.From, e.To)
+}
+
+// Operation is something the user can do to the page
+type Operation int
+
+const (
+	OpGrab     Operation = iota // drag and drop bubbles
+	OpSelect                    // add bubbles to the selection
+	OpCopy                      // duplicate bubbles
+	OpBubble                    // create a bubble of the opposite color
+	OpAssume                    // create an assumption pair, or add to it
+	OpUnit                      // create a unit of the same color
+	OpRename                    // type variable names
+	OpLoop                      // wrap bubbles in a loop of the opposite color
+	OpOfCourse                  // wrap bubbles in a blue loop
+	OpWhyNot                    // wrap bubbles in a red loop
+	OpDelete                    // delete bubbles
+	OpProve                     // start proving the statement
+	OpDone                      // go back to proof mode
+	OpEdit                      // go back to create mode to edit the statement
+	OpCut                       // move bubbles to the clipboard
+	OpPaste                     // paste bubbles from the clipboard
+)
+
+// the operations allowed in each mode
+var allowed = map[Mode][]Operation{
+	CreateMode:      {OpGrab, OpSelect, OpCopy, OpBubble, OpUnit, OpRename, OpLoop, OpOfCourse, OpWhyNot, OpDelete, OpProve, OpCut, OpPaste},
+	ProofMode:       {OpGrab, OpSelect, OpCopy, OpAssume, OpUnit, OpLoop, OpOfCourse, OpWhyNot, OpDelete, OpEdit, OpCut, OpPaste},
+	AssumptionMode:  {OpGrab, OpSelect, OpAssume, OpUnit, OpRename, OpLoop, OpOfCourse, OpWhyNot, OpDelete},
+	ContingencyMode: {OpGrab, OpSelect, OpCopy, OpBubble, OpUnit, OpRename, OpLoop, OpOfCourse, OpWhyNot, OpDelete, OpDone, OpCut, OpPaste},
+}
+
+// Allows returns whether an operation can be done in this mode
+func (m Mode) Allows(op Operation) bool {
+	for _, a := range allowed[m] {
+		if a == op {
+			return true
+		}
+	}
+	return false
+}
+
+// ModesAllowing lists the modes in which an operation can be done
+func ModesAllowing(op Operation) []Mode {
+	var modes []Mode
+	for _, m := range Modes {
+		if m.Allows(op) {
+			modes = append(modes, m)
+		}
+	}
+	return modes
+}
+
+// SetMode switches to another mode, doing whatever is needed to leave the
+// current one. Going back to create mode discards the proof history.
+func (pg *Page) SetMode(to Mode) error {
+	if !pg.Mode.CanTransition(to) {
+		Log.Warn("mode", eventlog.F("from", pg.Mode), eventlog.F("to", to), eventlog.F("result", "rejected"))
+		return &TransitionError{From: pg.Mode, To: to}
+	}
+	if pg.Mode == to {
+		return nil
+	}
+
+	switch pg.Mode {
+	case AssumptionMode:
+		pg.clearAssumption()
+	case ContingencyMode:
+		pg.Contingency = nil
+	}
+
+	switch to {
+	case CreateMode:
+		pg.clearAssumption()
+		pg.Theorem = nil
+		pg.History = nil
+	case ProofMode:
+		if pg.Mode == CreateMode {
+			pg.Theorem = clone(pg.Root)
+			pg.History = []*Bubble{clone(pg.Root)}
+			// pick up where we left off, if this statement was being proved before
+			if pg.Stash != nil && pg.Stash.Theorem.SameAs(pg.Theorem) {
+				pg.History = pg.Stash.History
+				pg.setRoot(pg.History[len(pg.History)-1])
+				pg.Stash = nil
+			}
+		}
+	case AssumptionMode:
+		if pg.AssumptionPair == nil || pg.AssumptionPair.Positive == nil || pg.AssumptionPair.Negative == nil {
+			return fmt.Errorf("can't enter assumption mode without an assumption pair")
+		}
+	}
+
+	Log.Info("mode", eventlog.F("from", pg.Mode), eventlog.F("to", to))
+	pg.Mode = to
+	return nil
+}
+
+// EditStatement goes back to create mode to edit the statement being proved.
+// The proof so far is stashed, and is picked back up if the same statement
+// is proved again.
+func (pg *Page) EditStatement() error {
+	if pg.Mode != ProofMode {
+		return &TransitionError{From: pg.Mode, To: CreateMode}
+	}
+	stash := &Proof{Theorem: pg.Theorem, History: pg.History}
+	if err := pg.SetMode(CreateMode); err != nil {
+		return err
+	}
+	pg.setRoot(stash.Theorem)
+	pg.Stash = stash
+	return nil
+}
+
+// EnterContingencyMode lets the inside of the given red loop be edited freely
+func (pg *Page) EnterContingencyMode(loop *Bubble) error {
+	if loop.Kind != RED {
+		return fmt.Errorf("contingencies have to be inside a red loop, not a %v one", loop.Kind)
+	}
+	if err := pg.SetMode(ContingencyMode); err != nil {
+		return err
+	}
+	pg.Contingency = loop
+	return nil
+}
+
+// InContingency returns whether a bubble is inside the red loop being edited
+func (pg *Page) InContingency(b *Bubble) bool {
+	return pg.Contingency != nil && b != pg.Contingency && pg.Contingency.IsAbove(b)
+}
@@ -0,0 +1,107 @@
+package page
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestTransitions(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	add(w, 0, 0, "A", WHITE)
+
+	err := pg.SetMode(AssumptionMode)
+	assert.ErrorContains(t, err, "can't go from Create mode to Assumption mode")
+	assert.Equal(t, pg.Mode, CreateMode)
+
+	assert.NilError(t, pg.SetMode(ProofMode))
+	assert.Equal(t, len(pg.History), 1)
+	assert.Equal(t, pg.History[0].Tolestra(), "A")
+
+	// there has to be a pair before going into assumption mode
+	assert.Assert(t, pg.SetMode(AssumptionMode) != nil)
+	assert.Equal(t, pg.Mode, ProofMode)
+
+	red := add(w, 0, 0, "", RED)
+	assert.NilError(t, pg.EnterContingencyMode(red))
+	assert.Equal(t, pg.Contingency, red)
+	assert.Assert(t, pg.SetMode(CreateMode) != nil)
+	assert.Assert(t, pg.SetMode(AssumptionMode) != nil)
+	assert.NilError(t, pg.SetMode(ProofMode))
+	assert.Assert(t, pg.Contingency == nil)
+
+	assert.NilError(t, pg.SetMode(CreateMode))
+	assert.Assert(t, pg.History == nil)
+}
+
+func TestAllows(t *testing.T) {
+	assert.Assert(t, CreateMode.Allows(OpBubble))
+	assert.Assert(t, !ProofMode.Allows(OpBubble))
+	assert.Assert(t, !ProofMode.Allows(OpRename))
+	assert.Assert(t, AssumptionMode.Allows(OpRename))
+	assert.Assert(t, !AssumptionMode.Allows(OpCopy))
+	assert.DeepEqual(t, ModesAllowing(OpDone), []Mode{ContingencyMode})
+}
+
+func TestEditStatement(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	a := add(w, 0, 0, "A", WHITE)
+
+	assert.Assert(t, pg.EditStatement() != nil)
+	assert.NilError(t, pg.SetMode(ProofMode))
+	assert.Equal(t, pg.Theorem.Tolestra(), "A")
+
+	pg.Execute(func() { pg.Loop(BLACK, a) })
+	pg.Execute(func() { pg.Loop(WHITE, a.Parent) })
+	assert.Equal(t, len(pg.History), 3)
+
+	// the statement goes back to how it was, and the proof is stashed
+	assert.NilError(t, pg.EditStatement())
+	assert.Equal(t, pg.Mode, CreateMode)
+	assert.Equal(t, pg.Root.Tolestra(), "A")
+	assert.Assert(t, pg.Theorem == nil)
+	assert.Equal(t, len(pg.Stash.History), 3)
+
+	// proving the same statement again picks the proof back up
+	assert.NilError(t, pg.SetMode(ProofMode))
+	assert.Equal(t, len(pg.History), 3)
+	assert.Equal(t, pg.Root.Tolestra(), pg.History[2].Tolestra())
+	assert.Assert(t, pg.Stash == nil)
+
+	// but a different statement starts from scratch
+	assert.NilError(t, pg.EditStatement())
+	pg.Root.Children[0].Children[0].Variable = "B"
+	assert.NilError(t, pg.SetMode(ProofMode))
+	assert.Equal(t, len(pg.History), 1)
+	assert.Equal(t, pg.Theorem.Tolestra(), "B")
+}
+
+func TestRewind(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	a := add(w, 0, 0, "A", WHITE)
+	assert.Assert(t, pg.Rewind(0) != nil)
+
+	assert.NilError(t, pg.SetMode(ProofMode))
+	pg.Execute(func() { pg.Loop(BLACK, a) })
+	pg.Execute(func() { pg.Loop(WHITE, a.Parent) })
+	assert.Equal(t, len(pg.History), 3)
+	assert.Assert(t, pg.Rewind(3) != nil)
+
+	assert.NilError(t, pg.Rewind(1))
+	assert.Equal(t, len(pg.History), 2)
+	assert.Assert(t, pg.Root.SameAs(pg.History[1]))
+	assert.NilError(t, pg.Validate())
+
+	// the history is a record of the steps, so the statement is a copy
+	pg.Execute(func() {
+		pg.Root.Iterate(func(b *Bubble) {
+			if b.Variable == "A" {
+				b.Variable = "B"
+			}
+		})
+	})
+	assert.Equal(t, pg.History[1].Tolestra(), "A")
+}
@@ -2,10 +2,11 @@ package page
 
 import (
 	"fmt"
+	"strings"
+	"vll/eventlog"
 
 	"github.com/faiface/pixel/pixelgl"
 	"github.com/faiface/pixel/text"
-	"golang.org/x/image/font/basicfont"
 )
 
 const (
@@ -17,6 +18,12 @@ type Pair struct {
 	Negative *Bubble
 }
 
+// Proof is a statement along with the steps taken so far to prove it
+type Proof struct {
+	Theorem *Bubble
+	History []*Bubble
+}
+
 type Page struct {
 	Root          *Bubble
 	Grabbed       *Bubble
@@ -26,10 +33,39 @@ type Page struct {
 	Highlighted   []*Bubble
 	win           *pixelgl.Window
 	Atlas         *text.Atlas
+	Camera        *Camera
+	Theme         *Theme
 
-	Mode           string
-	AssumptionMode bool
+	// what the page is called if it doesn't have a file
+	Name string
+	// the file the page is saved in, if it has one
+	File string
+
+	Mode           Mode
 	AssumptionPair *Pair
+	// the red loop being edited in contingency mode
+	Contingency *Bubble
+	// the statement as it was when proof mode was entered
+	Theorem *Bubble
+	// a snapshot of the statement after every step of the proof, starting
+	// with the statement being proved
+	History []*Bubble
+	// the proofs of the lemmas used on the page, along with the lemmas they
+	// use, so that the proof can be checked without the pages they're from
+	Lemmas []*Proof
+	// a proof put aside to edit its statement, which is picked back up if
+	// the same statement is proved again
+	Stash *Proof
+	// the bubbles which were last copied or cut
+	Clipboard []*Bubble
+	// the history as it was when Lemma last found the proof checked out, so
+	// that it isn't checked again every time a tab is drawn
+	checked []*Bubble
+	// every bubble in the statement, by ID
+	index map[ID]*Bubble
+	// how big bubbles are drawn while they're animated, from 0 to 1; bubbles
+	// which aren't in it are drawn at their full size
+	scale map[*Bubble]float64
 
 	unprocessedBubbles []*Bubble
 }
@@ -76,17 +112,38 @@ type Page struct {
 //   happening in each, but with opposite polarities. (cursor should change type to indicate this)
 
 func NewPage(win *pixelgl.Window) *Page {
-	basicAtlas := text.NewAtlas(basicfont.Face7x13, text.ASCII)
-
-	page := &Page{win: win, Atlas: basicAtlas}
+	basicAtlas := text.NewAtlas(face, text.ASCII, symbolRunes())
 
-	page.Root = &Bubble{
-		Kind: BACKGROUND,
+	page := &Page{win: win, Atlas: basicAtlas, Camera: NewCamera(width, height), Theme: DefaultTheme}
+	if win != nil {
+		page.Camera.Resize(int(win.Bounds().W()), int(win.Bounds().H()))
 	}
-	page.Mode = "Create"
+
+	page.Root = newBubble(0, 0, "", BACKGROUND)
+	page.reindex()
+	page.Mode = CreateMode
 	return page
 }
 
+// NewProofPage makes a page proving the given formula, written in Tolestra's
+// notation. An empty formula proves from nothing but a unit.
+func NewProofPage(win *pixelgl.Window, statement string) (*Page, error) {
+	var bubbles []*Bubble
+	if strings.TrimSpace(statement) != "" {
+		var err error
+		if bubbles, err = ParseTolestra(statement); err != nil {
+			return nil, err
+		}
+	}
+	pg := NewPage(win)
+	w := pg.Root.Insert(newBubble(0, 0, "", WHITE))
+	for _, b := range bubbles {
+		w.Insert(b)
+	}
+	pg.reindex()
+	return pg, pg.SetMode(ProofMode)
+}
+
 func (pg *Page) NewBubble(x, y int, v string, k Kind) *Bubble {
 	newb := newBubble(x, y, v, k)
 	pg.unprocessedBubbles = append(pg.unprocessedBubbles, newb)
@@ -120,70 +177,8 @@ func (pg *Page) Grab(bub *Bubble, x, y int) {
 	pg.Highlighted = []*Bubble{bub}
 }
 
-//   If it's in a white bubble, then it stays part of its parent if the nearest alternative is "above" it
-//     and it only becomes detached if the nearest alternative is a white region below its parent.
-//     If it's dropped over a black bubble below it, it checks if the bubble is its opposite.
-//       If so, both are removed. (ideally, there should be a way to make them "attract" while it's still grabbed)
-//   If it's a black bubble, then it stays part of its parent if the nearest alternative is "below" it
-//     and it only becomes detached if the nearest alternative is a black region above its parent.
-func (pg *Page) CanPlaceAt(other *Bubble) bool {
-	if pg.Grabbed != nil && pg.Grabbed.Parent == other {
-		return true
-	}
-	if pg.GrabbedParent != nil {
-		switch pg.GrabbedParent.Kind {
-		case WHITE, BLUE:
-			if pg.GrabbedParent.IsAbove(other) {
-
-				between := other
-				for !between.IsMult() {
-					between = between.Parent
-				}
-				for between != pg.GrabbedParent {
-					if !between.IsMult() {
-						fmt.Println("between")
-						return false
-					}
-					between = between.Parent
-				}
-
-				if other.Kind == WHITE {
-					return true
-				}
-				if other.Kind == BLACK || other.Kind == RED {
-					fmt.Println("almost")
-					fmt.Println(pg.Grabbed.Tolestra())
-					fmt.Println(other.Opposite())
-					if pg.Grabbed.Tolestra() == other.Opposite() {
-						other.Parent.Detach(other)
-						pg.GrabbedParent.Detach(pg.Grabbed)
-						pg.Grabbed = nil
-						pg.GrabbedParent = nil
-						pg.Highlighted = nil
-						return true
-					}
-				}
-			}
-			return false
-		case BLACK, RED:
-			if other.IsAbove(pg.GrabbedParent) && other.Kind == BLACK {
-				between := other
-				for between != pg.GrabbedParent {
-					if !between.IsMult() {
-						return false
-					}
-					between = between.Parent
-				}
-				return true
-			}
-			return false
-		}
-	}
-	return false
-}
-
 func (pg *Page) ReleaseInto(b *Bubble) {
-	fmt.Println("releasing")
+	Log.Debug("release", Field("bubble", pg.Grabbed), Field("into", b))
 	if pg.Grabbed != nil && b != nil {
 		parent := pg.GrabbedParent
 		// if dropped into a variable, find a more appropriate parent to place it into
@@ -192,7 +187,7 @@ func (pg *Page) ReleaseInto(b *Bubble) {
 				b = b.Parent
 			} else {
 				// place a buffer loop around the variable
-				fmt.Println("buffer")
+				Log.Debug("buffer", Field("around", b))
 				loop := pg.NewBubble(b.X, b.Y, "", b.Kind)
 				pg.Place(b.Parent, loop)
 				pg.Delete(b)
@@ -204,7 +199,6 @@ func (pg *Page) ReleaseInto(b *Bubble) {
 			if parent != nil {
 				parent.Detach(pg.Grabbed)
 			}
-			fmt.Println("replace")
 			pg.Place(b, pg.Grabbed)
 		}
 		pg.Highlighted = []*Bubble{pg.Grabbed}
@@ -214,7 +208,7 @@ func (pg *Page) ReleaseInto(b *Bubble) {
 }
 
 func (pg *Page) Place(parent, b *Bubble) {
-	fmt.Println("placing")
+	Log.Debug("place", Field("parent", parent), Field("bubble", b))
 	if b.AssumptionPair != nil && parent.AssumptionPair != nil {
 		parent.AssumptionPair.Insert(b.AssumptionPair)
 	}
@@ -222,7 +216,7 @@ func (pg *Page) Place(parent, b *Bubble) {
 }
 
 func (pg *Page) Delete(b *Bubble) {
-	fmt.Println("deleting")
+	Log.Debug("delete", Field("bubble", b), Field("parent", b.Parent))
 	if b.AssumptionPair != nil {
 		if b.AssumptionPair != pg.AssumptionPair.Positive && b.AssumptionPair != pg.AssumptionPair.Negative {
 			b.AssumptionPair.Parent.Detach(b.AssumptionPair)
@@ -231,14 +225,14 @@ func (pg *Page) Delete(b *Bubble) {
 	b.Parent.Detach(b)
 }
 
-func (pg *Page) Loop(loopKind Kind, bubbles ...*Bubble) {
-	// check that the  bubbles all have the same parent
-	parent := bubbles[0].Parent
-	for _, bubble := range bubbles {
-		if bubble.Parent != parent {
-			return
-		}
+// Loop wraps bubbles which share a parent in a new loop
+func (pg *Page) Loop(loopKind Kind, bubbles ...*Bubble) error {
+	if err := pg.CheckLoop(bubbles...); err != nil {
+		Log.Info("loop", eventlog.F("kind", loopKind.String()), eventlog.F("bubbles", len(bubbles)), eventlog.F("result", "rejected"), eventlog.F("reason", err))
+		return err
 	}
+	parent := bubbles[0].Parent
+	Log.Info("loop", eventlog.F("kind", loopKind.String()), eventlog.F("bubbles", len(bubbles)), Field("parent", parent))
 
 	var innerLoop *Bubble
 	if len(bubbles) > 1 {
@@ -247,7 +241,6 @@ func (pg *Page) Loop(loopKind Kind, bubbles ...*Bubble) {
 
 	outerLoop := pg.NewBubble(parent.X, parent.Y, "", loopKind)
 	pg.ProcessNewBubbles()
-	fmt.Println("has this", outerLoop.AssumptionPair)
 	pg.Place(parent, outerLoop)
 	if innerLoop != nil {
 		pg.Place(outerLoop, innerLoop)
@@ -264,18 +257,19 @@ func (pg *Page) Loop(loopKind Kind, bubbles ...*Bubble) {
 
 	outerLoop.CenterAroundChildren()
 	pg.Highlighted = []*Bubble{outerLoop}
+	return nil
 }
 
 func (pg *Page) ProcessNewBubbles() {
-	if pg.AssumptionMode {
-		fmt.Println("need to process", len(pg.unprocessedBubbles), "bubbles")
+	if pg.Mode == AssumptionMode {
+		Log.Debug("mirror", eventlog.F("bubbles", len(pg.unprocessedBubbles)))
 		for _, b := range pg.unprocessedBubbles {
 			if b != pg.AssumptionPair.Positive && b != pg.AssumptionPair.Negative {
 				var bub *Bubble
 				if pg.AssumptionPair.Positive.IsAbove(b) {
-					bub = newBubble(pg.AssumptionPair.Negative.X, pg.AssumptionPair.Negative.Y, b.Variable, b.OppositeKind())
+					bub = newBubble(pg.AssumptionPair.Negative.X, pg.AssumptionPair.Negative.Y, b.Variable, b.Kind.Dual())
 				} else {
-					bub = newBubble(pg.AssumptionPair.Positive.X, pg.AssumptionPair.Positive.Y, b.Variable, b.OppositeKind())
+					bub = newBubble(pg.AssumptionPair.Positive.X, pg.AssumptionPair.Positive.Y, b.Variable, b.Kind.Dual())
 				}
 				if b.Parent != nil {
 					bub.Parent = b.Parent.AssumptionPair
@@ -292,19 +286,121 @@ func (pg *Page) ProcessNewBubbles() {
 func (pg *Page) Execute(f func()) {
 	// if in assumption mode, disable othe actions
 	if len(pg.Highlighted) > 0 {
-		if pg.AssumptionMode && !pg.InAssumption(pg.Highlighted[0]) && !pg.InAssumption(pg.GrabbedParent) {
+		if pg.Mode == AssumptionMode && !pg.InAssumption(pg.Highlighted[0]) && !pg.InAssumption(pg.GrabbedParent) {
 			return
 		}
+		// likewise for contingency mode, except for new bubbles that haven't been placed yet
+		if pg.Mode == ContingencyMode && !pg.InContingency(pg.GrabbedParent) {
+			for _, highlighted := range pg.Highlighted {
+				if highlighted.Parent != nil && !pg.InContingency(highlighted) {
+					return
+				}
+			}
+		}
 	}
 
 	f()
 	pg.ProcessNewBubbles()
 	pg.NormalizeHeight()
+	pg.reindex()
+	pg.recordStep()
+
+	if validateAfterExecute {
+		if err := pg.Validate(); err != nil {
+			Log.Error("validate", eventlog.F("reason", err))
+			panic(err)
+		}
+	}
+}
+
+// validateAfterExecute panics if the statement is left invalid by Execute,
+// which is turned on in debug builds and tests
+var validateAfterExecute = false
+
+// setRoot replaces the whole statement with a copy of the given one, whose
+// bubbles keep their IDs
+func (pg *Page) setRoot(b *Bubble) {
+	pg.Root = clone(b)
+	pg.Root.normalizeDepth()
+	pg.NormalizeHeight()
+	pg.reindex()
+	pg.Grabbed = nil
+	pg.GrabbedParent = nil
+	pg.Highlighted = nil
+}
+
+// recordStep adds the statement to the history if it changed while proving
+func (pg *Page) recordStep() {
+	if pg.Mode == CreateMode || len(pg.History) == 0 {
+		return
+	}
+	if !pg.History[len(pg.History)-1].SameAs(pg.Root) {
+		pg.History = append(pg.History, clone(pg.Root))
+		Log.Info("step", eventlog.F("number", len(pg.History)-1), eventlog.F("goal", pg.Root.Tolestra()))
+	}
 }
 
+// Rewind goes back to an earlier step of the proof, forgetting the steps after it
+func (pg *Page) Rewind(step int) error {
+	if pg.Mode != ProofMode {
+		return fmt.Errorf("can only go back to an earlier step while proving, not in %v mode", pg.Mode)
+	}
+	if step < 0 || step >= len(pg.History) {
+		return fmt.Errorf("there's no step %v", step)
+	}
+	Log.Info("rewind", eventlog.F("step", step), eventlog.F("goal", pg.History[step].Tolestra()))
+	pg.History = pg.History[:step+1]
+	pg.setRoot(pg.History[step])
+	return nil
+}
+
+// CheckAssume returns why an assumption pair can't be made between a white
+// bubble and the black bubble it's in, or nil if it can
+func (pg *Page) CheckAssume(positive, negative *Bubble) error {
+	if pg.Mode != ProofMode {
+		return fmt.Errorf("assumptions can only be made while proving, not in %v mode", pg.Mode)
+	}
+	if positive == nil || positive.Kind != WHITE || positive.Variable != "" {
+		return reject(positive, "an assumption has to be made from a White bubble")
+	}
+	if negative == nil || positive.Parent != negative || negative.Kind != BLACK {
+		return reject(negative, "an assumption has to be made into the Black bubble around it")
+	}
+	return nil
+}
+
+// Assume makes an assumption pair: an empty white bubble at (px, py) in
+// positive, and an empty black bubble at (nx, ny) in negative, the black bubble
+// around it. The page goes into assumption mode, so that the two can be filled
+// in together.
+func (pg *Page) Assume(positive, negative *Bubble, px, py, nx, ny int) error {
+	if err := pg.CheckAssume(positive, negative); err != nil {
+		Log.Info("assume", Field("positive", positive), Field("negative", negative), eventlog.F("result", "rejected"), eventlog.F("reason", err))
+		return err
+	}
+	Log.Info("assume", Field("positive", positive), Field("negative", negative))
+	newPositive := pg.NewBubble(px, py, "", WHITE)
+	positive.Insert(newPositive)
+	newNegative := pg.NewBubble(nx, ny, "", BLACK)
+	negative.Insert(newNegative)
+	newPositive.AssumptionPair = newNegative
+	newNegative.AssumptionPair = newPositive
+	pg.AssumptionPair = &Pair{Positive: newPositive, Negative: newNegative}
+	pg.reindex()
+	return pg.SetMode(AssumptionMode)
+}
+
+// ExitAssumptionMode goes back to proof mode, and also cancels an assumption
+// pair that's still being dragged out
 func (pg *Page) ExitAssumptionMode() {
+	if pg.Mode == AssumptionMode {
+		pg.SetMode(ProofMode)
+	}
+	pg.clearAssumption()
+}
+
+func (pg *Page) clearAssumption() {
 	pg.AssumptionPair = nil
-	pg.AssumptionMode = false
 	pg.Root.Iterate(func(b *Bubble) {
 		b.AssumptionPair = nil
 	})
@@ -0,0 +1,234 @@
+package page
+
+import (
+	"errors"
+	"fmt"
+)
+
+// checker follows a proof one statement at a time. Besides the steps
+// StepBetween finds, the editor lets an assumption be filled in after it's
+// made, a red loop around a unit be filled in with anything in contingency
+// mode, and a lemma proved on another page be put into a White bubble, and
+// each of these has to be followed too.
+type checker struct {
+	// the statements proved from nothing, which can be used as lemmas
+	lemmas []*Bubble
+	// the assumption or contingency still being filled in, if there is one
+	open string
+	// what the statement was before the assumption was made
+	base *Bubble
+	// the path to the White bubble the assumption was made in, or to the
+	// red loop of the contingency
+	at []int
+}
+
+// follows returns what turns one statement of a proof into the next, or false
+// if nothing does. Once an assumption or contingency is started, the
+// statements after it can carry on filling it in, which they're said to
+// continue rather than being steps of their own.
+func (c *checker) follows(before, after *Bubble) (op string, continued, ok bool) {
+	switch c.open {
+	case "assume":
+		if assumedAt(c.base, after, c.at) {
+			return "assume", true, true
+		}
+	case "contingency":
+		if confinedTo(before, after, c.at) {
+			return "contingency", true, true
+		}
+	}
+	if s, ok := StepBetween(before, after); ok {
+		c.open = ""
+		if s.Op == "assume" {
+			c.open, c.base, c.at = "assume", before, s.Subject
+		}
+		return s.Op, false, true
+	}
+
+	var paths [][]int
+	before.Iterate(func(b *Bubble) { paths = append(paths, pathOf(b)) })
+	for _, path := range paths {
+		b, _ := follow(before, path)
+		switch {
+		case assumedAt(before, after, path):
+			c.open, c.base, c.at = "assume", before, path
+			return "assume", false, true
+		case b.Kind == RED && holdsUnits(b) && confinedTo(before, after, path):
+			c.open, c.at = "contingency", path
+			return "contingency", false, true
+		case c.lemmaAt(before, after, path):
+			c.open = ""
+			return "lemma", false, true
+		}
+	}
+	return "", false, false
+}
+
+// check returns why a proof doesn't follow from its theorem, or nil if it does
+func (c *checker) check(p *Proof) error {
+	if p.Theorem == nil || len(p.History) == 0 {
+		return errors.New("there's no proof")
+	}
+	if !p.History[0].SameAs(p.Theorem) {
+		return fmt.Errorf("the proof doesn't start from %v", formulaOf(p.Theorem))
+	}
+	for i := 1; i < len(p.History); i++ {
+		if _, _, ok := c.follows(p.History[i-1], p.History[i]); !ok {
+			return fmt.Errorf("step %v doesn't follow from the one before it", i)
+		}
+	}
+	return nil
+}
+
+// checkLemmas checks the proofs of lemmas, each of which can use the ones
+// before it, and returns the statements they prove
+func checkLemmas(lemmas []*Proof) ([]*Bubble, error) {
+	var proved []*Bubble
+	for i, lemma := range lemmas {
+		if lemma.Theorem == nil || !onlyUnits(lemma.Theorem) {
+			return nil, fmt.Errorf("lemma %v isn't proved from nothing", i+1)
+		}
+		c := &checker{lemmas: proved}
+		if err := c.check(lemma); err != nil {
+			return nil, fmt.Errorf("lemma %v: %v", i+1, err)
+		}
+		proved = append(proved, lemma.History[len(lemma.History)-1])
+	}
+	return proved, nil
+}
+
+// CheckProof returns why the proof on the page doesn't follow from the
+// statement it started with, or nil if it does
+func (pg *Page) CheckProof() error {
+	if pg.Mode == CreateMode {
+		return errors.New("there's no proof in create mode")
+	}
+	lemmas, err := checkLemmas(pg.Lemmas)
+	if err != nil {
+		return err
+	}
+	c := &checker{lemmas: lemmas}
+	if err := c.check(&Proof{Theorem: pg.Theorem, History: pg.History}); err != nil {
+		return err
+	}
+	if !pg.History[len(pg.History)-1].SameAs(pg.Root) {
+		return errors.New("the proof doesn't end with the statement")
+	}
+	return nil
+}
+
+// assumedAt returns whether after is before with an assumption pair added: a
+// White bubble in the White bubble at path, and its dual in the Black bubble
+// around that
+func assumedAt(before, after *Bubble, path []int) bool {
+	w, ok := follow(before, path)
+	if !ok || w.Kind != WHITE || w.Variable != "" || w.Parent == nil || w.Parent.Kind != BLACK {
+		return false
+	}
+	grown, ok := follow(after, path)
+	if !ok || grown.Parent == nil {
+		return false
+	}
+	for i, positive := range grown.Children {
+		if positive.Kind != WHITE || positive.Variable != "" {
+			continue
+		}
+		mirror := dual(positive)
+		for j, negative := range grown.Parent.Children {
+			if negative.Kind != BLACK || !negative.SameAs(mirror) {
+				continue
+			}
+			// taking the pair back out has to leave the statement as it was
+			trimmed := clone(after)
+			w, _ := follow(trimmed, path)
+			w.Children = withoutChild(w.Children, i)
+			w.Parent.Children = withoutChild(w.Parent.Children, j)
+			if trimmed.SameAs(before) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// confinedTo returns whether two statements only differ inside the red loop at path
+func confinedTo(before, after *Bubble, path []int) bool {
+	loop, ok := follow(after, path)
+	if !ok || loop.Kind != RED {
+		return false
+	}
+	return hollow(before, path).SameAs(hollow(after, path))
+}
+
+// lemmaAt returns whether after is before with a lemma put into the White
+// bubble at path
+func (c *checker) lemmaAt(before, after *Bubble, path []int) bool {
+	b, _ := follow(before, path)
+	if len(c.lemmas) == 0 || b.Kind != WHITE && b.Kind != BACKGROUND || b.Variable != "" {
+		return false
+	}
+	grown, ok := follow(after, path)
+	if !ok || !hollow(before, path).SameAs(hollow(after, path)) {
+		return false
+	}
+	// match up what was already there, and the rest is what was put in
+	left := make(map[string]int)
+	for _, child := range b.Children {
+		left[child.structure()]++
+	}
+	var added []*Bubble
+	for _, child := range grown.Children {
+		if s := child.structure(); left[s] > 0 {
+			left[s]--
+		} else {
+			added = append(added, child)
+		}
+	}
+	for _, n := range left {
+		if n > 0 {
+			return false
+		}
+	}
+	if len(added) == 0 {
+		return false
+	}
+	statement := &Bubble{Kind: BACKGROUND, Children: added}
+	for _, lemma := range c.lemmas {
+		if statement.SameAs(lemma) {
+			return true
+		}
+	}
+	return false
+}
+
+// holdsUnits returns whether there's something in a loop, and it's nothing
+// but units
+func holdsUnits(loop *Bubble) bool {
+	for _, child := range loop.Children {
+		if !onlyUnits(child) {
+			return false
+		}
+	}
+	return len(loop.Children) > 0
+}
+
+// dual is a copy of a bubble with every kind in it swapped for its dual, the
+// way the negative side of an assumption mirrors the positive side
+func dual(b *Bubble) *Bubble {
+	d := clone(b)
+	d.Iterate(func(bub *Bubble) { bub.Kind = bub.Kind.Dual() })
+	return d
+}
+
+// hollow is a copy of a statement with everything inside the bubble at path taken out
+func hollow(b *Bubble, path []int) *Bubble {
+	h := clone(b)
+	inside, _ := follow(h, path)
+	inside.Children = nil
+	return h
+}
+
+// withoutChild is the bubbles other than the one at i
+func withoutChild(bubbles []*Bubble, i int) []*Bubble {
+	return append(append([]*Bubble{}, bubbles[:i]...), bubbles[i+1:]...)
+}
@@ -0,0 +1,103 @@
+package page
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// contingency fills a red loop around a unit with two variables
+func contingency(t *testing.T) *Page {
+	t.Helper()
+	pg := emptyProofPage()
+	unit := pg.Root.Children[0]
+	pg.Execute(func() { pg.Loop(RED, unit) })
+	red := unit.Parent
+	assert.NilError(t, pg.EnterContingencyMode(red))
+	pg.Select(unit)
+	for _, v := range []string{"A", "B"} {
+		pg.Execute(func() {
+			pg.Grab(pg.NewBubble(0, 0, v, WHITE), 0, 0)
+			pg.ReleaseInto(red)
+		})
+	}
+	assert.NilError(t, pg.SetMode(ProofMode))
+	return pg
+}
+
+// compoundAssumption assumes the tensor of two variables, built up in
+// assumption mode
+func compoundAssumption(t *testing.T) *Page {
+	t.Helper()
+	pg := emptyProofPage()
+	_, err := pg.TakeStep(Step{Op: "loop", Subject: []int{0}})
+	assert.NilError(t, err)
+	w := pg.Root.Children[0].Children[0]
+	assert.NilError(t, pg.Assume(w, w.Parent, 0, 0, 0, 0))
+	for _, v := range []string{"A", "B"} {
+		positive := pg.AssumptionPair.Positive
+		pg.Select(positive)
+		pg.Execute(func() {
+			pg.Grab(pg.NewBubble(positive.X, positive.Y, v, WHITE), positive.X, positive.Y)
+			pg.ReleaseInto(positive)
+		})
+	}
+	pg.ExitAssumptionMode()
+	return pg
+}
+
+// excludedMiddle proves (A + ~A), to be used as a lemma
+func excludedMiddle(t *testing.T) *Page {
+	t.Helper()
+	pg := emptyProofPage()
+	prove(t, pg, "loop [0]", "assume [0 0] A")
+	return pg
+}
+
+func TestCheckProof(t *testing.T) {
+	pg := contingency(t)
+	assert.Equal(t, len(pg.History), 4)
+	assert.NilError(t, pg.CheckProof())
+
+	pg = compoundAssumption(t)
+	assert.Equal(t, pg.Root.Tolestra(), "((A * B) + (~A + ~B))")
+	_, ok := StepBetween(pg.History[1], pg.History[3])
+	assert.Assert(t, !ok)
+	assert.NilError(t, pg.CheckProof())
+
+	// but nothing else is
+	forged := pg.History[len(pg.History)-1].Copy()
+	forged.Children[0].Insert(newBubble(0, 0, "C", WHITE))
+	pg.History = append(pg.History, forged)
+	pg.setRoot(forged)
+	assert.ErrorContains(t, pg.CheckProof(), "step 4 doesn't follow")
+}
+
+func TestCheckProofWithLemma(t *testing.T) {
+	lemma := excludedMiddle(t)
+	pg, err := NewProofPage(nil, "B")
+	assert.NilError(t, err)
+	pg.Execute(func() { assert.NilError(t, pg.UseLemma(lemma, pg.Root.Children[0], 0, 0)) })
+	assert.NilError(t, pg.CheckProof())
+
+	// the lemma's proof is saved along with the page's, and checked when it's loaded
+	pg.File = filepath.Join(t.TempDir(), "uses-lemma.vll")
+	assert.NilError(t, pg.Save())
+	loaded, err := LoadPage(nil, pg.File)
+	assert.NilError(t, err)
+	assert.Equal(t, len(loaded.Lemmas), 1)
+
+	// without it, the step can't be followed
+	pg.Lemmas = nil
+	assert.ErrorContains(t, pg.CheckProof(), "step 1 doesn't follow")
+	assert.NilError(t, pg.Save())
+	_, err = LoadPage(nil, pg.File)
+	assert.ErrorContains(t, err, "step 1 doesn't follow")
+
+	// and neither can a lemma that isn't proved
+	pg.Lemmas = []*Proof{{Theorem: lemma.Theorem, History: lemma.History[:1]}}
+	assert.ErrorContains(t, pg.CheckProof(), "step 1 doesn't follow")
+	assert.NilError(t, os.Remove(pg.File))
+}
@@ -0,0 +1,209 @@
+package page
+
+import (
+	"flag"
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+var (
+	proofSeed = flag.Int64("seed", 1, "seed for the randomly generated proofs")
+	proofs    = flag.Int("proofs", 150, "how many random proofs to check")
+	proofSize = flag.Int("proof-size", 8, "how many steps each random proof has")
+	// how many sequents the prover looks at for a step with exponentials,
+	// before it gives up and calls the step inconclusive
+	proverBudget = flag.Int("prover-budget", 2000, "how hard to look for proofs of steps with exponentials")
+)
+
+// randomFormula makes a formula with atoms A, B and C. The exponentials are
+// only used if asked for, since the prover can only give up on some of them.
+func randomFormula(r *rand.Rand, depth int, exponentials bool) *formula {
+	if depth == 0 || r.Intn(4) == 0 {
+		switch r.Intn(10) {
+		case 0:
+			return &formula{op: '1'}
+		case 1:
+			return &formula{op: '0'}
+		}
+		return &formula{op: 'a', name: string(rune('A' + r.Intn(3))), neg: r.Intn(2) == 0}
+	}
+	if exponentials && r.Intn(4) == 0 {
+		return &formula{op: []rune("!?")[r.Intn(2)], args: []*formula{randomFormula(r, depth-1, exponentials)}}
+	}
+	f := &formula{op: []rune("*+")[r.Intn(2)]}
+	for i := 0; i < 2+r.Intn(2); i++ {
+		f.args = append(f.args, randomFormula(r, depth-1, exponentials))
+	}
+	return f
+}
+
+func hasExponentials(f *formula) bool {
+	if f.op == '!' || f.op == '?' {
+		return true
+	}
+	for _, arg := range f.args {
+		if hasExponentials(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// check replays a proof, and returns the first property it breaks: the tree
+// has to stay valid, and every step has to follow from the one before it, so
+// that a statement can never be turned into one which isn't a consequence of
+// it. Steps which can't be taken any more are skipped. The prover only
+// contracts so many times, and only looks so hard, so a step with
+// exponentials it can't prove is handed to inconclusive, if it's given,
+// rather than failing.
+func check(statement string, steps []Step, inconclusive func(string)) (err error) {
+	pg, err := NewProofPage(nil, statement)
+	if err != nil {
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panicked: %v", r)
+		}
+	}()
+	for i, s := range steps {
+		before := statementFormula(pg.Root)
+		if _, err := pg.TakeStep(s); err != nil {
+			continue
+		}
+		if err := pg.Validate(); err != nil {
+			return fmt.Errorf("step %v (%v): %v", i, s, err)
+		}
+		after := statementFormula(pg.Root)
+		exponential := hasExponentials(before) || hasExponentials(after)
+		if !exponential && entails(before, after) || exponential && entailsWithin(before, after, *proverBudget) {
+			continue
+		}
+		err := fmt.Errorf("step %v (%v): %v doesn't follow from %v", i, s, after, before)
+		if !exponential {
+			return err
+		}
+		if inconclusive != nil {
+			inconclusive(err.Error())
+		}
+	}
+	return nil
+}
+
+// smaller lists formulas which are a bit simpler than f: each connective
+// replaced by one of its arguments, or with an argument left out
+func smaller(f *formula) []*formula {
+	var candidates []*formula
+	for i, arg := range f.args {
+		candidates = append(candidates, arg)
+		if len(f.args) > 2 {
+			candidates = append(candidates, &formula{op: f.op, args: without(f.args, i)})
+		}
+		for _, simpler := range smaller(arg) {
+			args := append([]*formula{}, f.args...)
+			args[i] = simpler
+			candidates = append(candidates, &formula{op: f.op, args: args})
+		}
+	}
+	return candidates
+}
+
+// shrink finds a smaller statement and proof which still fail, by repeatedly
+// leaving out steps and simplifying the statement
+func shrink(statement string, steps []Step, fails func(string, []Step) bool) (string, []Step) {
+	for shrunk := true; shrunk; {
+		shrunk = false
+		for i := range steps {
+			fewer := append(append([]Step{}, steps[:i]...), steps[i+1:]...)
+			if fails(statement, fewer) {
+				steps = fewer
+				shrunk = true
+				break
+			}
+		}
+		formulas, err := parseFormulas(statement)
+		if err != nil || len(formulas) != 1 {
+			break
+		}
+		for _, simpler := range smaller(formulas[0]) {
+			if fails(simpler.String(), steps) {
+				statement = simpler.String()
+				shrunk = true
+				break
+			}
+		}
+	}
+	return statement, steps
+}
+
+func breaksProperty(statement string, steps []Step) bool {
+	return check(statement, steps, nil) != nil
+}
+
+func TestRandomProofs(t *testing.T) {
+	count := *proofs
+	if testing.Short() {
+		count /= 10
+	}
+	r := rand.New(rand.NewSource(*proofSeed))
+	checked, inconclusive := 0, 0
+	for n := 0; n < count; n++ {
+		statement := randomFormula(r, 3, n%3 == 0).String()
+		pg, err := NewProofPage(nil, statement)
+		if err != nil {
+			t.Fatalf("can't make a page for %v: %v", statement, err)
+		}
+
+		// take random legal steps, checking everything at the end
+		var steps []Step
+		for i := 0; i < *proofSize; i++ {
+			legal := pg.LegalSteps("A", "B", "C")
+			if len(legal) == 0 {
+				break
+			}
+			s := legal[r.Intn(len(legal))]
+			pg.TakeStep(s)
+			steps = append(steps, s)
+		}
+
+		err = check(statement, steps, func(reason string) {
+			inconclusive++
+			t.Logf("inconclusive: proof of %v: %v", statement, reason)
+		})
+		if err != nil {
+			statement, steps = shrink(statement, steps, breaksProperty)
+			lines := make([]string, len(steps))
+			for i, s := range steps {
+				lines[i] = "  " + s.String()
+			}
+			t.Fatalf("proof of %v breaks a property: %v\nsteps:\n%v", statement, check(statement, steps, nil), strings.Join(lines, "\n"))
+		}
+		checked += len(steps)
+	}
+	t.Logf("%v steps checked, %v of them inconclusive", checked, inconclusive)
+}
+
+func TestShrink(t *testing.T) {
+	// pretend that unit steps are broken whenever B is in the statement
+	fails := func(statement string, steps []Step) bool {
+		for _, s := range steps {
+			if s.Op == "unit" && strings.Contains(statement, "B") {
+				return true
+			}
+		}
+		return false
+	}
+	steps := []Step{
+		{Op: "loop", Subject: []int{0, 0}},
+		{Op: "unit", Subject: []int{0}},
+		{Op: "move", Subject: []int{0, 0, 1}, Target: []int{0, 1}},
+		{Op: "unit", Subject: []int{0, 1}},
+	}
+	statement, steps := shrink("((A * B) + (~B * C))", steps, fails)
+	assert.Equal(t, statement, "B")
+	assert.Equal(t, fmt.Sprint(steps), "[unit [0 1]]")
+}
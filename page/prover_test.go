@@ -0,0 +1,323 @@
+package page
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// String writes a formula in Tolestra's notation
+func (f *formula) String() string {
+	switch f.op {
+	case 'a':
+		if f.neg {
+			return "~" + f.name
+		}
+		return f.name
+	case '1', '0':
+		return string(f.op)
+	case '!', '?':
+		return string(f.op) + f.args[0].String()
+	}
+	args := make([]string, len(f.args))
+	for i, arg := range f.args {
+		args[i] = arg.String()
+	}
+	return "(" + strings.Join(args, " "+string(f.op)+" ") + ")"
+}
+
+// statementFormula is the formula a statement stands for. An empty page is 1.
+func statementFormula(root *Bubble) *formula {
+	s := root.Tolestra()
+	if s == "" {
+		return &formula{op: '1'}
+	}
+	formulas, err := parseFormulas(s)
+	if err != nil || len(formulas) != 1 {
+		panic("statement " + s + " can't be read back")
+	}
+	return formulas[0]
+}
+
+// entails decides whether a proves b in multiplicative linear logic with the
+// mix rule, which is the logic the editor works in: an empty region means the
+// same thing whatever its color, so 1 and 0 (bottom) are the same.
+func entails(a, b *formula) bool {
+	return entailsWithin(a, b, -1)
+}
+
+// entailsWithin is entails, giving up and saying no after the prover has
+// looked at budget sequents, if budget isn't negative
+func entailsWithin(a, b *formula, budget int) bool {
+	a, b = normal(a), normal(b)
+	if a.String() == b.String() {
+		return true
+	}
+	// a step usually only changes one part of a statement, and every
+	// connective is monotone, so proving that part is enough
+	if a.op == b.op && (a.op == '*' || a.op == '+') {
+		if ra, rb, ok := cancel(a, b); ok && entailsWithin(ra, rb, budget) {
+			return true
+		}
+	} else if a.op == b.op && (a.op == '!' || a.op == '?') && entailsWithin(a.args[0], b.args[0], budget) {
+		return true
+	}
+	// dereliction, on either side
+	if b.op == '?' && entailsWithin(a, b.args[0], budget) || a.op == '!' && entailsWithin(a.args[0], b, budget) {
+		return true
+	}
+	return provableWithin([]*formula{a.dual(), b}, budget)
+}
+
+// normal is a formula with tensors in tensors and pars in pars flattened,
+// their units left out, and their arguments in order, which all leave it
+// meaning the same thing
+func normal(f *formula) *formula {
+	if f.op != '*' && f.op != '+' {
+		n := &formula{op: f.op, name: f.name, neg: f.neg}
+		for _, arg := range f.args {
+			n.args = append(n.args, normal(arg))
+		}
+		return n
+	}
+	n := &formula{op: f.op}
+	for _, arg := range f.args {
+		arg = normal(arg)
+		switch {
+		case arg.op == '1' || arg.op == '0':
+		case arg.op == f.op:
+			n.args = append(n.args, arg.args...)
+		default:
+			n.args = append(n.args, arg)
+		}
+	}
+	switch len(n.args) {
+	case 0:
+		return &formula{op: '1'}
+	case 1:
+		return n.args[0]
+	}
+	sort.Slice(n.args, func(i, j int) bool { return n.args[i].String() < n.args[j].String() })
+	return n
+}
+
+// cancel leaves out the arguments two tensors or two pars have in common, if
+// they have any
+func cancel(a, b *formula) (ra, rb *formula, ok bool) {
+	left := make(map[string]int)
+	for _, arg := range b.args {
+		left[arg.String()]++
+	}
+	ra, rb = &formula{op: a.op}, &formula{op: b.op}
+	for _, arg := range a.args {
+		if s := arg.String(); left[s] > 0 {
+			left[s]--
+			ok = true
+		} else {
+			ra.args = append(ra.args, arg)
+		}
+	}
+	for _, arg := range b.args {
+		if s := arg.String(); left[s] > 0 {
+			left[s]--
+			rb.args = append(rb.args, arg)
+		}
+	}
+	return normal(ra), normal(rb), ok
+}
+
+// provable decides whether a one-sided sequent of formulas, with units, is
+// provable with mix. Since contraction can be used any number of times, a
+// sequent with exponentials is only searched for a proof with at most
+// maxContractions of them, which is plenty for the statements in the corpus.
+func provable(sequent []*formula) bool {
+	return provableWithin(sequent, -1)
+}
+
+// provableWithin is provable, giving up and saying no after looking at
+// budget sequents, if budget isn't negative
+func provableWithin(sequent []*formula, budget int) bool {
+	return (&prover{seen: make(map[string]bool), contractions: maxContractions, budget: budget}).prove(sequent)
+}
+
+// maxContractions is how many times provable copies a why-not formula
+const maxContractions = 2
+
+type prover struct {
+	seen map[string]bool
+	// how many more contractions can be used
+	contractions int
+	// how many more sequents can be looked at, or -1 for as many as it takes
+	budget int
+}
+
+func key(sequent []*formula) string {
+	strs := make([]string, len(sequent))
+	for i, f := range sequent {
+		strs[i] = f.String()
+	}
+	sort.Strings(strs)
+	return strings.Join(strs, ", ")
+}
+
+func (p *prover) prove(sequent []*formula) bool {
+	k := strconv.Itoa(p.contractions) + ": " + key(sequent)
+	if result, ok := p.seen[k]; ok {
+		return result
+	}
+	if p.budget == 0 {
+		return false
+	}
+	if p.budget > 0 {
+		p.budget--
+	}
+	p.seen[k] = false
+	result := p.search(sequent)
+	p.seen[k] = result
+	return result
+}
+
+func (p *prover) search(sequent []*formula) bool {
+	// every atom has to be used up by an axiom with its dual, unless it can
+	// be weakened away or copied
+	exponential := false
+	balance := make(map[string]int)
+	copyable := make(map[string]bool)
+	for _, f := range sequent {
+		countAtoms(f, false, balance, copyable)
+		exponential = exponential || hasExponentials(f)
+	}
+	for name, n := range balance {
+		if n != 0 && !copyable[name] {
+			return false
+		}
+	}
+
+	// par and bottom can always be taken apart first
+	for i, f := range sequent {
+		rest := without(sequent, i)
+		switch f.op {
+		case '+':
+			return p.prove(append(rest, f.args...))
+		case '0':
+			return p.prove(rest)
+		}
+	}
+
+	// with mix, the empty sequent is provable, 1 can be dropped, and a
+	// sequent holds if two parts of it do
+	if len(sequent) == 0 {
+		return true
+	}
+	for i, f := range sequent {
+		if f.op == '1' {
+			return p.prove(without(sequent, i))
+		}
+	}
+	for split := 1; split < (1<<len(sequent))-1; split++ {
+		var left, right []*formula
+		for j, g := range sequent {
+			if split&(1<<j) != 0 {
+				left = append(left, g)
+			} else {
+				right = append(right, g)
+			}
+		}
+		if p.prove(left) && p.prove(right) {
+			return true
+		}
+	}
+	if len(sequent) == 2 && sequent[0].op == 'a' && sequent[1].op == 'a' &&
+		sequent[0].name == sequent[1].name && sequent[0].neg != sequent[1].neg {
+		return true
+	}
+
+	if exponential && p.exponentials(sequent) {
+		return true
+	}
+
+	for i, f := range sequent {
+		if f.op != '*' {
+			continue
+		}
+		first := f.args[0]
+		second := &formula{op: '*', args: f.args[1:]}
+		if len(f.args) == 2 {
+			second = f.args[1]
+		}
+		rest := without(sequent, i)
+		for split := 0; split < 1<<len(rest); split++ {
+			left := []*formula{first}
+			right := []*formula{second}
+			for j, g := range rest {
+				if split&(1<<j) != 0 {
+					left = append(left, g)
+				} else {
+					right = append(right, g)
+				}
+			}
+			if p.prove(left) && p.prove(right) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// exponentials tries the rules for of-course and why-not: an of-course
+// formula is promoted when everything else is a why-not, and a why-not
+// formula is weakened away, derelicted, or copied
+func (p *prover) exponentials(sequent []*formula) bool {
+	for i, f := range sequent {
+		rest := without(sequent, i)
+		switch f.op {
+		case '!':
+			promotable := true
+			for _, g := range rest {
+				promotable = promotable && g.op == '?'
+			}
+			if promotable && p.prove(append(rest, f.args[0])) {
+				return true
+			}
+		case '?':
+			if p.prove(rest) || p.prove(append(rest, f.args[0])) {
+				return true
+			}
+			if p.contractions > 0 {
+				p.contractions--
+				copied := p.prove(append(append(rest, f), f))
+				p.contractions++
+				if copied {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// countAtoms adds up the atoms in f which have to be used exactly once, and
+// notes the names of the ones in a why-not, which can be used any number of
+// times
+func countAtoms(f *formula, whyNot bool, balance map[string]int, copyable map[string]bool) {
+	whyNot = whyNot || f.op == '?'
+	if f.op == 'a' {
+		switch {
+		case whyNot:
+			copyable[f.name] = true
+		case f.neg:
+			balance[f.name]--
+		default:
+			balance[f.name]++
+		}
+	}
+	for _, arg := range f.args {
+		countAtoms(arg, whyNot, balance, copyable)
+	}
+}
+
+func without(sequent []*formula, i int) []*formula {
+	rest := make([]*formula, 0, len(sequent)-1)
+	rest = append(rest, sequent[:i]...)
+	return append(rest, sequent[i+1:]...)
+}
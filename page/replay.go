@@ -0,0 +1,263 @@
+package page
+
+import (
+	"fmt"
+	"math"
+	"time"
+	"vll/eventlog"
+)
+
+const (
+	// how many steps are played a second, to begin with
+	defaultReplaySpeed = 0.75
+	minReplaySpeed     = 0.1
+	maxReplaySpeed     = 8
+)
+
+// Replay plays the steps of a proof back as an animation. Each step is shown
+// in two halves: first the bubble being moved travels to where it's going and
+// anything that's removed shrinks away, and then anything new grows in.
+type Replay struct {
+	History []*Bubble
+	// the step being shown, which goes from History[Step] to History[Step+1]
+	Step int
+	// how far through the step the animation is, from 0 to 1
+	Progress float64
+	Playing  bool
+	// how many steps are played a second
+	Speed float64
+	// the page the animation is drawn on, which shares the camera of the page
+	// being replayed
+	Page *Page
+
+	// the animation of each step, made the first time it's shown
+	animations map[int]*stepAnimation
+}
+
+// NewReplay starts playing back the proof on a page from the beginning
+func NewReplay(pg *Page) (*Replay, error) {
+	if pg.Mode != ProofMode || len(pg.History) < 2 {
+		return nil, fmt.Errorf("there aren't any steps to play back yet")
+	}
+	r := &Replay{
+		History:    pg.History,
+		Playing:    true,
+		Speed:      defaultReplaySpeed,
+		Page:       NewPage(pg.win),
+		animations: make(map[int]*stepAnimation),
+	}
+	r.Page.Name = pg.Name
+	r.Page.Camera = pg.Camera
+	r.Page.Theme = pg.Theme
+	r.Page.Mode = ProofMode
+	Log.Info("replay", eventlog.F("steps", len(r.History)-1))
+	r.show()
+	return r, nil
+}
+
+// Steps is how many steps there are to play back
+func (r *Replay) Steps() int {
+	return len(r.History) - 1
+}
+
+// Finished returns whether the last step has been played
+func (r *Replay) Finished() bool {
+	return r.Step == r.Steps()
+}
+
+// Advance moves the animation along by however much time has passed, if it's
+// playing. It stops once the last step has been played.
+func (r *Replay) Advance(elapsed time.Duration) {
+	if !r.Playing || r.Finished() {
+		r.Playing = false
+		return
+	}
+	r.Progress += elapsed.Seconds() * r.Speed
+	for r.Progress >= 1 && !r.Finished() {
+		r.Progress--
+		r.Step++
+	}
+	if r.Finished() {
+		r.Progress = 0
+		r.Playing = false
+	}
+	r.show()
+}
+
+// Toggle plays or pauses the animation. Playing it once it's finished starts
+// it over.
+func (r *Replay) Toggle() {
+	if r.Finished() && !r.Playing {
+		r.Step, r.Progress = 0, 0
+	}
+	r.Playing = !r.Playing
+	r.show()
+}
+
+// Forward pauses, and skips to the end of the step being shown
+func (r *Replay) Forward() {
+	r.Playing = false
+	if !r.Finished() {
+		r.Step++
+	}
+	r.Progress = 0
+	r.show()
+}
+
+// Back pauses, and goes back to the start of the step being shown, or to the
+// step before it if it's already at the start
+func (r *Replay) Back() {
+	r.Playing = false
+	if r.Progress == 0 && r.Step > 0 {
+		r.Step--
+	}
+	r.Progress = 0
+	r.show()
+}
+
+// SetSpeed changes how fast the animation plays, within reason
+func (r *Replay) SetSpeed(speed float64) {
+	r.Speed = math.Max(minReplaySpeed, math.Min(maxReplaySpeed, speed))
+}
+
+// show puts the frame for the animation as it is onto the replay's page
+func (r *Replay) show() {
+	var root *Bubble
+	var scale map[*Bubble]float64
+	if r.Progress == 0 {
+		root = clone(r.History[r.Step])
+	} else {
+		a, ok := r.animations[r.Step]
+		if !ok {
+			a = animateStep(r.History[r.Step], r.History[r.Step+1])
+			r.animations[r.Step] = a
+		}
+		root, scale = a.frame(r.Progress)
+	}
+	r.Page.Root = root
+	r.Page.Root.normalizeDepth()
+	r.Page.NormalizeHeight()
+	r.Page.reindex()
+	r.Page.scale = scale
+}
+
+// stepAnimation is how a step moves the bubbles of a statement about. The
+// statements before and after the step share the IDs of the bubbles the step
+// doesn't add or remove.
+type stepAnimation struct {
+	before, after *Bubble
+	// the bubbles the step removes, and the ones it adds
+	removed, added map[ID]bool
+	// the bubble being moved, and how far it goes
+	moved  ID
+	dx, dy int
+}
+
+// animateStep works out what a step between two statements does to their
+// bubbles. If it's not a single step that can be taken while proving, the
+// first statement just shrinks away and the second one grows in.
+func animateStep(from, to *Bubble) *stepAnimation {
+	a := &stepAnimation{before: clone(from), after: clone(to)}
+	s, ok := StepBetween(from, to)
+	if !ok {
+		return a.compare()
+	}
+	pg := NewPage(nil)
+	pg.Root = clone(a.before)
+	pg.Root.normalizeDepth()
+	pg.NormalizeHeight()
+	pg.reindex()
+	pg.Mode = ProofMode
+	pg.History = []*Bubble{clone(pg.Root)}
+	if _, err := pg.TakeStep(s); err != nil {
+		return a.compare()
+	}
+	a.after = pg.Root
+
+	if s.Op == "move" {
+		subject, _ := follow(a.before, s.Subject)
+		target, _ := follow(a.before, s.Target)
+		sx, sy := subject.CenterOfMass()
+		tx, ty := target.CenterOfMass()
+		a.moved, a.dx, a.dy = subject.ID, tx-sx, ty-sy
+		// where the bubble is moved to is where it ends up
+		if moved, ok := find(a.after, subject.ID); ok {
+			moved.Iterate(func(b *Bubble) {
+				b.X += a.dx
+				b.Y += a.dy
+			})
+		}
+	}
+	return a.compare()
+}
+
+// compare finds the bubbles which are only before the step, or only after it
+func (a *stepAnimation) compare() *stepAnimation {
+	ids := func(root *Bubble) map[ID]bool {
+		found := make(map[ID]bool)
+		root.Iterate(func(b *Bubble) { found[b.ID] = true })
+		return found
+	}
+	before, after := ids(a.before), ids(a.after)
+	a.removed, a.added = make(map[ID]bool), make(map[ID]bool)
+	for id := range before {
+		if !after[id] {
+			a.removed[id] = true
+		}
+	}
+	for id := range after {
+		if !before[id] {
+			a.added[id] = true
+		}
+	}
+	return a
+}
+
+// frame is the statement part of the way through the step, along with how big
+// each of its bubbles is drawn
+func (a *stepAnimation) frame(progress float64) (*Bubble, map[*Bubble]float64) {
+	scale := make(map[*Bubble]float64)
+	if progress < 0.5 {
+		// the bubble being moved travels, while anything removed shrinks away
+		root := clone(a.before)
+		travel := ease(progress / 0.5)
+		if moved, ok := find(root, a.moved); ok {
+			moved.Iterate(func(b *Bubble) {
+				b.X += int(travel * float64(a.dx))
+				b.Y += int(travel * float64(a.dy))
+			})
+		}
+		shrink := math.Min(1, (0.5-progress)/0.25)
+		root.Iterate(func(b *Bubble) {
+			if a.removed[b.ID] {
+				scale[b] = shrink
+			}
+		})
+		return root, scale
+	}
+	// anything new grows in
+	root := clone(a.after)
+	grow := ease((progress - 0.5) / 0.5)
+	root.Iterate(func(b *Bubble) {
+		if a.added[b.ID] {
+			scale[b] = grow
+		}
+	})
+	return root, scale
+}
+
+// ease speeds up and then slows down over the course of an animation
+func ease(t float64) float64 {
+	return t * t * (3 - 2*t)
+}
+
+// find looks for the bubble with the given ID inside a bubble
+func find(root *Bubble, id ID) (*Bubble, bool) {
+	var found *Bubble
+	root.Iterate(func(b *Bubble) {
+		if b.ID == id {
+			found = b
+		}
+	})
+	return found, found != nil
+}
@@ -0,0 +1,80 @@
+package page
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestReplay(t *testing.T) {
+	pg, err := NewProofPage(nil, "(A * (B + ~A))")
+	assert.NilError(t, err)
+	_, err = NewReplay(pg)
+	assert.ErrorContains(t, err, "aren't any steps")
+
+	prove(t, pg, "move [0 0 0] -> [0 0 1 1]", "loop [0 0]")
+	a, _ := follow(pg.History[0], []int{0, 0, 0})
+	dual, _ := follow(pg.History[0], []int{0, 0, 1, 1})
+	r, err := NewReplay(pg)
+	assert.NilError(t, err)
+	assert.Equal(t, r.Steps(), 2)
+	assert.Assert(t, r.Page.Root.SameAs(pg.History[0]))
+
+	// the A travels to its dual, and then the two shrink away
+	r.Playing = false
+	r.Progress = 0.25
+	r.show()
+	moved, ok := find(r.Page.Root, a.ID)
+	assert.Assert(t, ok)
+	assert.Assert(t, moved.X != a.X || moved.Y != a.Y || a.X == dual.X && a.Y == dual.Y)
+	assert.Equal(t, r.Page.scale[moved], 1.0)
+	r.Progress = 0.45
+	r.show()
+	moved, _ = find(r.Page.Root, a.ID)
+	assert.Assert(t, r.Page.scale[moved] < 0.5)
+	r.Progress = 0.75
+	r.show()
+	assert.Equal(t, r.Page.Root.Tolestra(), "B")
+
+	// the loop grows in
+	r.Forward()
+	assert.Equal(t, r.Step, 1)
+	r.Progress = 0.6
+	r.show()
+	grown := 0
+	for _, scale := range r.Page.scale {
+		assert.Assert(t, scale < 0.5)
+		grown++
+	}
+	assert.Assert(t, grown > 0)
+
+	// playing goes through to the end, and then starts over
+	r.Back()
+	r.Back()
+	assert.Equal(t, r.Step, 0)
+	r.SetSpeed(100)
+	assert.Equal(t, r.Speed, float64(maxReplaySpeed))
+	r.Toggle()
+	r.Advance(time.Second)
+	assert.Assert(t, r.Finished())
+	assert.Assert(t, !r.Playing)
+	assert.Assert(t, r.Page.Root.SameAs(pg.Root))
+	r.Toggle()
+	assert.Equal(t, r.Step, 0)
+	assert.Assert(t, r.Playing)
+}
+
+func TestDrawScaled(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	a := add(w, 0, 0, "A", WHITE)
+	b := add(w, 200, 0, "B", WHITE)
+	pg.NormalizeHeight()
+	assert.Equal(t, pg.BelongsTo(a.X, a.Y), a)
+
+	// a bubble shrunk away isn't there any more
+	pg.scale = map[*Bubble]float64{a: 0}
+	assert.Assert(t, pg.BelongsTo(a.X, a.Y) != a)
+	assert.Equal(t, pg.BelongsTo(b.X, b.Y), b)
+}
@@ -0,0 +1,98 @@
+package page
+
+import "fmt"
+
+// Rejection explains why a rule doesn't allow something, pointing at the
+// bubble that's in the way
+type Rejection struct {
+	Reason string
+	At     *Bubble
+}
+
+func (r *Rejection) Error() string {
+	return r.Reason
+}
+
+func reject(at *Bubble, format string, args ...interface{}) *Rejection {
+	return &Rejection{Reason: fmt.Sprintf(format, args...), At: at}
+}
+
+// CheckPlace returns why the grabbed bubble can't be dropped into other, or
+// nil if it can. Like everything that checks a move, it never changes anything.
+func (pg *Page) CheckPlace(other *Bubble) error {
+	_, err := pg.DropDestination(other)
+	return err
+}
+
+// checkMove decides whether b, which is in from, can be moved into other, and
+// whether doing so annihilates the two.
+//
+// A bubble in a white bubble can move further inside it, into a white region
+// below it, or onto a black bubble below it which is its opposite, in which
+// case both are removed. A bubble in a black bubble can only move out of it,
+// into a black region above it. Either way, it can't cross a blue or red loop.
+func (pg *Page) checkMove(b, from, other *Bubble) (annihilate bool, err error) {
+	if b == nil || other == nil {
+		return false, reject(nil, "nothing is being moved")
+	}
+	if b.Parent == other {
+		return false, nil
+	}
+	if b.IsAbove(other) {
+		return false, reject(other, "a bubble can't be dropped inside itself")
+	}
+	if from == nil {
+		return false, reject(b, "a new bubble can't be placed while proving")
+	}
+	if pg.Mode == AssumptionMode && pg.InAssumption(from) && !pg.InAssumption(other) {
+		return false, reject(other, "the target is outside the assumption")
+	}
+
+	switch from.Kind {
+	case WHITE, BLUE:
+		if !from.IsAbove(other) {
+			return false, reject(from, "a bubble in a %v bubble can only move further inside it", from.Kind)
+		}
+		// a loop being dropped into doesn't count as being crossed
+		inner := other
+		for !inner.IsMult() && inner != from {
+			inner = inner.Parent
+		}
+		if err := crossable(inner, from); err != nil {
+			return false, err
+		}
+		switch other.Kind {
+		case WHITE:
+			return false, nil
+		case BLACK, RED:
+			if b.Tolestra() != other.Opposite() {
+				return false, reject(other, "%v is not the dual of %v", b.Tolestra(), other.Tolestra())
+			}
+			return true, nil
+		}
+		return false, reject(other, "a bubble in a %v bubble can only be dropped into a White bubble, or onto its dual", from.Kind)
+	case BLACK, RED:
+		if !other.IsAbove(from) {
+			return false, reject(from, "a bubble in a %v bubble can only move out of it", from.Kind)
+		}
+		if other.Kind != BLACK {
+			return false, reject(other, "a bubble in a %v bubble can only be dropped into a Black bubble around it", from.Kind)
+		}
+		if err := crossable(from, other); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+	return false, reject(b, "bubbles on the background can't be moved while proving")
+}
+
+// crossable returns why a bubble can't move between bottom and top, which has
+// to be above it, because of a loop in between
+func crossable(bottom, top *Bubble) error {
+	for between := bottom; between != top; between = between.Parent {
+		if !between.IsMult() {
+			return reject(between, "cannot cross a %v boundary", between.Kind)
+		}
+	}
+	return nil
+}
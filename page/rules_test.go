@@ -0,0 +1,93 @@
+package page
+
+import (
+	"errors"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// rejectedAt checks that err is a rejection pointing at the given bubble
+func rejectedAt(t *testing.T, err error, at *Bubble, reason string) {
+	t.Helper()
+	var rejection *Rejection
+	assert.Assert(t, errors.As(err, &rejection), "%v", err)
+	assert.Equal(t, rejection.At, at)
+	assert.ErrorContains(t, err, reason)
+}
+
+func TestCheckPlace(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	a := add(w, 0, 0, "A", WHITE)
+	b := add(w, 0, 0, "", BLACK)
+	notA := add(b, 0, 0, "B", BLACK)
+	inner := add(b, 0, 0, "", WHITE)
+	red := add(w, 0, 0, "", RED)
+	behindRed := add(red, 0, 0, "", BLACK)
+	pg.SetMode(ProofMode)
+
+	pg.Grab(a, 0, 0)
+	assert.NilError(t, pg.CheckPlace(w))
+	assert.NilError(t, pg.CheckPlace(inner))
+	rejectedAt(t, pg.CheckPlace(notA), notA, "A is not the dual of ~B")
+	rejectedAt(t, pg.CheckPlace(behindRed), red, "cannot cross a Red boundary")
+	rejectedAt(t, pg.CheckPlace(pg.Root), w, "can only move further inside it")
+
+	// black bubbles can only move out, into black
+	pg.Grab(notA, 0, 0)
+	rejectedAt(t, pg.CheckPlace(inner), b, "can only move out of it")
+	rejectedAt(t, pg.CheckPlace(w), w, "can only be dropped into a Black bubble")
+
+	mid := add(b, 0, 0, "", WHITE)
+	innerBlack := add(mid, 0, 0, "", BLACK)
+	c := add(innerBlack, 0, 0, "C", BLACK)
+	pg.Grab(c, 0, 0)
+	assert.NilError(t, pg.CheckPlace(b))
+
+	// checking never changes anything
+	notA.Variable = "A"
+	pg.Grab(a, 0, 0)
+	assert.NilError(t, pg.CheckPlace(notA))
+	d, err := pg.DropDestination(notA)
+	assert.NilError(t, err)
+	assert.Equal(t, d.Effect, Annihilate)
+	assert.Equal(t, notA.Parent, b)
+	assert.Equal(t, pg.Grabbed, a)
+	assert.NilError(t, pg.ApplyMove(d))
+	assert.Assert(t, notA.Parent != b)
+}
+
+func TestCheckDeleteRejection(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	a := add(w, 0, 0, "A", WHITE)
+	pg.SetMode(ProofMode)
+	pg.Select(a)
+	rejectedAt(t, pg.CheckDelete(), a, "can only be deleted while proving")
+}
+
+// Moving a bubble out of a Black bubble is the switch rule, which can be
+// applied once for every White bubble in between, so it's allowed through
+// any number of multiplicative layers, but never across a loop.
+func TestCheckPlaceSwitch(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	outer := add(w, 0, 0, "", BLACK)
+	mid := add(add(outer, 0, 0, "", WHITE), 0, 0, "", BLACK)
+	inner := add(add(mid, 0, 0, "", WHITE), 0, 0, "", BLACK)
+	a := add(inner, 0, 0, "A", WHITE)
+	blue := add(mid, 0, 0, "", BLUE)
+	behindBlue := add(add(blue, 0, 0, "", WHITE), 0, 0, "", BLACK)
+	b := add(behindBlue, 0, 0, "B", WHITE)
+	pg.SetMode(ProofMode)
+
+	pg.Grab(a, 0, 0)
+	assert.NilError(t, pg.CheckPlace(mid))
+	assert.NilError(t, pg.CheckPlace(outer))
+	rejectedAt(t, pg.CheckPlace(w), w, "can only be dropped into a Black bubble")
+
+	pg.Grab(b, 0, 0)
+	rejectedAt(t, pg.CheckPlace(mid), blue, "cannot cross a Blue boundary")
+	rejectedAt(t, pg.CheckPlace(outer), blue, "cannot cross a Blue boundary")
+}
@@ -0,0 +1,283 @@
+package page
+
+import (
+	"errors"
+	"vll/eventlog"
+)
+
+// Select replaces the selection
+func (pg *Page) Select(bubbles ...*Bubble) {
+	pg.Highlighted = nil
+	pg.AddToSelection(bubbles...)
+}
+
+// AddToSelection selects more bubbles, skipping ones that are already selected
+func (pg *Page) AddToSelection(bubbles ...*Bubble) {
+	for _, b := range bubbles {
+		if b != nil && !pg.IsHighlighted(b) {
+			pg.Highlighted = append(pg.Highlighted, b)
+		}
+	}
+}
+
+// Toggle selects a bubble if it isn't selected, and deselects it otherwise
+func (pg *Page) Toggle(b *Bubble) {
+	for i, bub := range pg.Highlighted {
+		if bub == b {
+			pg.Highlighted = append(pg.Highlighted[:i:i], pg.Highlighted[i+1:]...)
+			return
+		}
+	}
+	pg.Highlighted = append(pg.Highlighted, b)
+}
+
+// SelectSiblings extends the selection to everything sharing a parent with
+// the first selected bubble
+func (pg *Page) SelectSiblings() {
+	if len(pg.Highlighted) == 0 || pg.Highlighted[0].Parent == nil {
+		return
+	}
+	pg.Select(pg.Highlighted[0].Parent.Children...)
+}
+
+// SelectSubtree extends the selection to everything inside the selected bubbles
+func (pg *Page) SelectSubtree() {
+	for _, b := range pg.Highlighted {
+		b.Iterate(func(bub *Bubble) {
+			pg.AddToSelection(bub)
+		})
+	}
+}
+
+// SelectRect adds the bubbles inside a rectangle (in world coordinates) to the selection
+func (pg *Page) SelectRect(x0, y0, x1, y1 int) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	pg.selectInside(func(x, y int) bool {
+		return x0 <= x && x <= x1 && y0 <= y && y <= y1
+	})
+}
+
+// SelectLasso adds the bubbles inside a polygon (in world coordinates) to the selection
+func (pg *Page) SelectLasso(xs, ys []int) {
+	if len(xs) < 3 || len(xs) != len(ys) {
+		return
+	}
+	pg.selectInside(func(x, y int) bool {
+		// count how many edges a ray going right from the point crosses
+		inside := false
+		for i, j := 0, len(xs)-1; i < len(xs); j, i = i, i+1 {
+			if (ys[i] > y) != (ys[j] > y) &&
+				float64(x) < float64(xs[j]-xs[i])*float64(y-ys[i])/float64(ys[j]-ys[i])+float64(xs[i]) {
+				inside = !inside
+			}
+		}
+		return inside
+	})
+}
+
+// selectInside selects the outermost bubbles which are entirely inside a region
+func (pg *Page) selectInside(inside func(x, y int) bool) {
+	var visit func(b *Bubble)
+	visit = func(b *Bubble) {
+		enclosed := true
+		b.Iterate(func(bub *Bubble) {
+			if !inside(bub.X, bub.Y) {
+				enclosed = false
+			}
+		})
+		if enclosed {
+			pg.AddToSelection(b)
+			return
+		}
+		for _, child := range b.Children {
+			visit(child)
+		}
+	}
+	for _, child := range pg.Root.Children {
+		visit(child)
+	}
+}
+
+var errNothingSelected = errors.New("nothing is selected")
+
+func (pg *Page) isAssumptionPair(b *Bubble) bool {
+	return pg.AssumptionPair != nil && (b == pg.AssumptionPair.Positive || b == pg.AssumptionPair.Negative)
+}
+
+// CheckLoop returns why the given bubbles can't be wrapped in a loop, or nil if they can
+func (pg *Page) CheckLoop(bubbles ...*Bubble) error {
+	if len(bubbles) == 0 {
+		return errNothingSelected
+	}
+	parent := bubbles[0].Parent
+	for _, b := range bubbles {
+		if b == pg.Root {
+			return reject(b, "the background can't be looped")
+		}
+		if pg.isAssumptionPair(b) {
+			return reject(b, "the assumption pair can't be looped")
+		}
+		if b.Parent != parent {
+			return reject(b, "bubbles must share a parent")
+		}
+	}
+	if len(bubbles) > 1 && parent == pg.Root {
+		return errors.New("bubbles on the background can only be looped one at a time")
+	}
+	return nil
+}
+
+// CheckOfCourse returns why the given bubbles can't be wrapped in a blue loop,
+// or nil if they can. While proving, only empty white units and blue loops can
+// be, since a blue loop holding anything else doesn't follow from its inside.
+func (pg *Page) CheckOfCourse(bubbles ...*Bubble) error {
+	if err := pg.CheckLoop(bubbles...); err != nil {
+		return err
+	}
+	if pg.Mode != ProofMode {
+		return nil
+	}
+	for _, b := range bubbles {
+		unit := b.Kind == WHITE && b.Variable == "" && len(b.Children) == 0
+		if b.Kind != BLUE && !unit {
+			return reject(b, "only empty White units and Blue loops can be wrapped in a Blue loop while proving")
+		}
+	}
+	return nil
+}
+
+// CheckDelete returns why the selection can't be deleted in the current mode,
+// or nil if it can
+func (pg *Page) CheckDelete() error {
+	if len(pg.Highlighted) == 0 {
+		return errNothingSelected
+	}
+	for _, b := range pg.Highlighted {
+		if b == pg.Root {
+			return reject(b, "the background can't be deleted")
+		}
+		if pg.isAssumptionPair(b) {
+			return reject(b, "the assumption pair can't be deleted")
+		}
+	}
+	if pg.Mode == CreateMode || pg.Mode == ContingencyMode {
+		return nil
+	}
+
+	// a blue loop can only be deleted on its own, or along with things inside it
+	for _, b := range pg.Highlighted {
+		if b.Kind == BLUE {
+			if len(pg.Highlighted) > 1 && LCA(pg.Highlighted...) != b {
+				return reject(b, "a blue loop can only be deleted along with things inside it")
+			}
+			return nil
+		}
+	}
+	for _, b := range pg.Highlighted {
+		if b.Kind == RED {
+			if len(pg.Highlighted) > 1 {
+				return reject(b, "a red loop has to be deleted on its own")
+			}
+			if !removableRed(b) {
+				return reject(b, "a red loop can only be deleted around a black bubble holding nothing but red loops")
+			}
+			return nil
+		}
+		if !removableLoop(b) && !removableUnit(b) {
+			return reject(b, "a %v bubble can only be deleted while proving if it's a loop around a single bubble, or an empty unit inside a bubble of the same color", b.Kind)
+		}
+	}
+	return nil
+}
+
+// a loop around a single bubble
+func removableLoop(b *Bubble) bool {
+	return len(b.Children) == 1 && b.Variable == "" && b.Parent != nil && b.IsMult()
+}
+
+// an empty unit with a parent of the same color
+func removableUnit(b *Bubble) bool {
+	return len(b.Children) == 0 && b.Variable == "" && b.Parent != nil && b.Parent.Kind == b.Kind && b.IsMult()
+}
+
+// a red loop whose only child is black, and whose grandkids are red loops
+func removableRed(b *Bubble) bool {
+	if len(b.Children) != 1 || b.Children[0].Kind != BLACK {
+		return false
+	}
+	for _, grandkid := range b.Children[0].Children {
+		if grandkid.Kind != RED {
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteSelection deletes the selected bubbles in create mode, moving their
+// children up into their parents
+func (pg *Page) DeleteSelection() {
+	for _, highlighted := range pg.Highlighted {
+		newParent := highlighted.Parent
+		pg.Delete(highlighted)
+		for _, child := range highlighted.Children {
+			pg.Place(newParent, child)
+		}
+	}
+}
+
+// DeleteLoops deletes the selected loops in proof mode, in the ways which
+// don't change the meaning of the statement
+func (pg *Page) DeleteLoops() {
+	var blue *Bubble
+	for _, highlighted := range pg.Highlighted {
+		if pg.isAssumptionPair(highlighted) {
+			return
+		}
+
+		// if a blue loop is highlighted, along with any of its descendents (but nothing else), delete the entire bubble
+		if highlighted.Kind == BLUE {
+			blue = highlighted
+		}
+
+		if removableLoop(highlighted) {
+			child := highlighted.Children[0]
+			newParent := highlighted.Parent
+			pg.Delete(highlighted)
+			pg.Place(newParent, child)
+		}
+
+		// allow deletion of empty bubbles with a parent of the same color
+		if removableUnit(highlighted) {
+			pg.Delete(highlighted)
+		}
+	}
+	if blue != nil {
+		Log.Debug("delete-blue", Field("loop", blue), eventlog.F("selected", len(pg.Highlighted)))
+		if len(pg.Highlighted) == 1 {
+			for _, child := range blue.Children {
+				newParent := blue.Parent
+				pg.Delete(blue)
+				pg.Place(newParent, child)
+			}
+		} else {
+			if LCA(pg.Highlighted...) == blue {
+				pg.Delete(blue)
+			}
+		}
+	}
+	// only delete a red loop if it's child is black and its grandkids are red loops
+	if len(pg.Highlighted) == 1 && pg.Highlighted[0].Kind == RED {
+		subject := pg.Highlighted[0]
+		if removableRed(subject) {
+			child := subject.Children[0]
+			newParent := subject.Parent
+			pg.Delete(subject)
+			pg.Place(newParent, child)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package page
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestToggle(t *testing.T) {
+	pg := NewPage(nil)
+	a := add(pg.Root, 100, 100, "A", WHITE)
+	b := add(pg.Root, 200, 100, "B", WHITE)
+
+	pg.Toggle(a)
+	pg.Toggle(b)
+	assert.DeepEqual(t, pg.Highlighted, []*Bubble{a, b})
+	pg.Toggle(a)
+	assert.DeepEqual(t, pg.Highlighted, []*Bubble{b})
+}
+
+func TestSelectRect(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 100, 100, "", WHITE)
+	a := add(w, 110, 110, "A", WHITE)
+	b := add(pg.Root, 400, 400, "B", WHITE)
+
+	// only the outermost bubble is selected when all of it is inside
+	pg.SelectRect(150, 150, 50, 50)
+	assert.DeepEqual(t, pg.Highlighted, []*Bubble{w})
+
+	// part of a bubble being outside only selects what's inside
+	pg.Highlighted = nil
+	pg.SelectRect(105, 105, 120, 120)
+	assert.DeepEqual(t, pg.Highlighted, []*Bubble{a})
+
+	pg.SelectRect(0, 0, 500, 500)
+	assert.DeepEqual(t, pg.Highlighted, []*Bubble{a, w, b})
+}
+
+func TestSelectLasso(t *testing.T) {
+	pg := NewPage(nil)
+	a := add(pg.Root, 100, 100, "A", WHITE)
+	add(pg.Root, 300, 100, "B", WHITE)
+
+	// a triangle around A but not B
+	pg.SelectLasso([]int{50, 200, 50}, []int{50, 100, 150})
+	assert.DeepEqual(t, pg.Highlighted, []*Bubble{a})
+}
+
+func TestCheckLoop(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 100, 100, "", WHITE)
+	a := add(w, 100, 100, "A", WHITE)
+	b := add(pg.Root, 200, 100, "B", WHITE)
+
+	assert.ErrorContains(t, pg.CheckLoop(), "nothing is selected")
+	assert.ErrorContains(t, pg.CheckLoop(a, b), "bubbles must share a parent")
+	assert.ErrorContains(t, pg.CheckLoop(w, b), "one at a time")
+	assert.NilError(t, pg.CheckLoop(a))
+	assert.ErrorContains(t, pg.Loop(BLACK, a, b), "bubbles must share a parent")
+}
+
+func TestCheckDelete(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 100, 100, "", WHITE)
+	a := add(w, 100, 100, "A", WHITE)
+	pg.Select(a)
+	assert.NilError(t, pg.CheckDelete())
+
+	assert.NilError(t, pg.SetMode(ProofMode))
+	assert.ErrorContains(t, pg.CheckDelete(), "can only be deleted while proving")
+
+	pg.Execute(func() { pg.Loop(BLACK, a) })
+	pg.Select(a.Parent)
+	assert.NilError(t, pg.CheckDelete())
+}
@@ -0,0 +1,465 @@
+package page
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Step is one thing that can be done to the statement while proving. Bubbles
+// are referred to by their path of child indices from the root, so that a step
+// can be saved, and replayed on a copy of the statement.
+type Step struct {
+	// "move", "loop", "of-course", "why-not", "unit", "delete", "copy", "cut"
+	// or "assume", or one of the steps TakeStep returns to undo them, which
+	// can't be taken themselves: "unmove", "unloop", "remove", "put", "wrap"
+	// or "restore"
+	Op      string `json:"op"`
+	Subject []int  `json:"subject"`
+	// where a bubble is moved to
+	Target []int `json:"target,omitempty"`
+	// more bubbles looped along with the subject
+	With [][]int `json:"with,omitempty"`
+	// the variable an assumption is made of
+	Variable string `json:"variable,omitempty"`
+	// what the bubble at the subject is put back to by a restore step, the
+	// bubble put into it by a put step, or the loop put around the subject
+	// and the rest by a wrap step
+	Restore *savedBubble `json:"restore,omitempty"`
+}
+
+func (s Step) String() string {
+	str := fmt.Sprintf("%v %v", s.Op, s.Subject)
+	for _, path := range s.With {
+		str += fmt.Sprintf(" %v", path)
+	}
+	if s.Target != nil {
+		str += fmt.Sprintf(" -> %v", s.Target)
+	}
+	if s.Variable != "" {
+		str += " " + s.Variable
+	}
+	return str
+}
+
+// ParseStep reads a step written the way String writes it
+func ParseStep(str string) (Step, error) {
+	fields := strings.Fields(strings.NewReplacer("[", " [ ", "]", " ] ").Replace(str))
+	if len(fields) == 0 {
+		return Step{}, errors.New("empty step")
+	}
+	s := Step{Op: fields[0]}
+	var paths [][]int
+	var path []int
+	target := false
+	for _, field := range fields[1:] {
+		switch field {
+		case "[":
+			path = []int{}
+		case "]":
+			if target {
+				s.Target = path
+			} else {
+				paths = append(paths, path)
+			}
+			path = nil
+		case "->":
+			target = true
+		default:
+			if path == nil {
+				s.Variable = field
+				continue
+			}
+			i, err := strconv.Atoi(field)
+			if err != nil {
+				return Step{}, fmt.Errorf("bad path in step %q", str)
+			}
+			path = append(path, i)
+		}
+	}
+	if len(paths) == 0 {
+		return Step{}, fmt.Errorf("step %q doesn't say what it's done to", str)
+	}
+	s.Subject, s.With = paths[0], paths[1:]
+	return s, nil
+}
+
+// pathOf is the child indices leading from the root to b
+func pathOf(b *Bubble) []int {
+	path := []int{}
+	for ; b.Parent != nil; b = b.Parent {
+		for i, child := range b.Parent.Children {
+			if child == b {
+				path = append([]int{i}, path...)
+			}
+		}
+	}
+	return path
+}
+
+// follow finds the bubble a path leads to, if it still leads anywhere
+func follow(root *Bubble, path []int) (*Bubble, bool) {
+	for _, i := range path {
+		if i < 0 || i >= len(root.Children) {
+			return nil, false
+		}
+		root = root.Children[i]
+	}
+	return root, true
+}
+
+// LegalSteps lists every step that can be taken from the statement as it is,
+// with assumptions made of the given variables. It never changes anything.
+func (pg *Page) LegalSteps(variables ...string) []Step {
+	if pg.Mode != ProofMode {
+		return nil
+	}
+	highlighted := pg.Highlighted
+	defer func() { pg.Highlighted = highlighted }()
+
+	var steps []Step
+	var bubbles []*Bubble
+	pg.Root.Iterate(func(b *Bubble) {
+		if b != pg.Root {
+			bubbles = append(bubbles, b)
+		}
+	})
+	for _, b := range bubbles {
+		path := pathOf(b)
+		for _, d := range pg.Destinations(b) {
+			steps = append(steps, Step{Op: "move", Subject: path, Target: pathOf(d.Into)})
+		}
+		for _, op := range []string{"loop", "why-not", "of-course", "unit", "delete", "copy", "cut"} {
+			s := Step{Op: op, Subject: path}
+			if pg.CheckStep(s) == nil {
+				steps = append(steps, s)
+			}
+		}
+		if pg.CheckAssume(b, b.Parent) == nil {
+			for _, v := range variables {
+				steps = append(steps, Step{Op: "assume", Subject: path, Variable: v})
+			}
+		}
+	}
+	return steps
+}
+
+// subjects finds the bubbles a step is done to
+func (pg *Page) subjects(s Step) ([]*Bubble, error) {
+	var bubbles []*Bubble
+	for _, path := range append([][]int{s.Subject}, s.With...) {
+		b, ok := follow(pg.Root, path)
+		if !ok {
+			return nil, fmt.Errorf("there's no bubble at %v", path)
+		}
+		bubbles = append(bubbles, b)
+	}
+	if s.Op != "restore" && s.Op != "put" && bubbles[0] == pg.Root {
+		return nil, errors.New("the background can't be changed while proving")
+	}
+	return bubbles, nil
+}
+
+// CheckStep returns why a step can't be taken, or nil if it can. Like
+// everything that checks a move, it never changes anything.
+func (pg *Page) CheckStep(s Step) error {
+	if pg.Mode != ProofMode {
+		return fmt.Errorf("steps can only be taken while proving, not in %v mode", pg.Mode)
+	}
+	bubbles, err := pg.subjects(s)
+	if err != nil {
+		return err
+	}
+	subject := bubbles[0]
+
+	switch s.Op {
+	case "move":
+		target, ok := follow(pg.Root, s.Target)
+		if !ok {
+			return fmt.Errorf("there's no bubble at %v", s.Target)
+		}
+		_, err := pg.CheckMove(subject, target)
+		return err
+	case "loop":
+		return pg.CheckLoop(bubbles...)
+	case "why-not":
+		return pg.CheckLoop(subject)
+	case "of-course":
+		return pg.CheckOfCourse(subject)
+	case "unit":
+		if subject.Variable != "" {
+			return reject(subject, "a unit can't be put inside a variable")
+		}
+		return nil
+	case "assume":
+		return pg.CheckAssume(subject, subject.Parent)
+	case "delete", "copy", "cut":
+		highlighted := pg.Highlighted
+		defer func() { pg.Highlighted = highlighted }()
+		pg.Select(subject)
+		switch s.Op {
+		case "delete":
+			return pg.CheckDelete()
+		case "copy":
+			return pg.CheckCopy()
+		}
+		return pg.CheckCut()
+	case "unmove", "unloop", "remove", "put", "wrap", "restore":
+		return fmt.Errorf("%v only undoes a step, and can't be taken as one", s.Op)
+	}
+	return fmt.Errorf("unknown step %q", s.Op)
+}
+
+// TakeStep does a step the same way the editor does, and returns the step
+// which undoes it: a move is moved back, a loop is unlooped, what a step adds
+// is removed, and what it deletes is put back, except that bubbles which
+// annihilated each other are restored along with the whole statement. Only
+// undo takes those, since they'd change the statement any way they liked.
+func (pg *Page) TakeStep(s Step) (undo Step, err error) {
+	if err := pg.CheckStep(s); err != nil {
+		return Step{}, err
+	}
+	bubbles, _ := pg.subjects(s)
+	subject := bubbles[0]
+	paths := func(bubbles ...*Bubble) (first []int, rest [][]int) {
+		for _, b := range bubbles[1:] {
+			rest = append(rest, pathOf(b))
+		}
+		return pathOf(bubbles[0]), rest
+	}
+
+	switch s.Op {
+	case "move":
+		target, _ := follow(pg.Root, s.Target)
+		d, _ := pg.CheckMove(subject, target)
+		if d.Effect == Annihilate {
+			undo = Step{Op: "restore", Subject: []int{}, Restore: saveBubble(pg.Root)}
+		}
+		pg.Execute(func() { pg.ApplyMove(d) })
+		switch d.Effect {
+		case Move:
+			undo = Step{Op: "unmove", Subject: pathOf(subject), Target: pathOf(d.From)}
+		case Wrap:
+			// the loop put around the variable it was dropped on goes too
+			undo = Step{Op: "unmove", Subject: pathOf(subject), Target: pathOf(d.From), With: [][]int{pathOf(subject.Parent)}}
+		}
+	case "loop", "why-not", "of-course":
+		kind := map[string]Kind{"loop": subject.Kind.Polarity().Dual(), "why-not": RED, "of-course": BLUE}[s.Op]
+		pg.Execute(func() { pg.Loop(kind, bubbles...) })
+		loop := subject.Parent
+		undo = Step{Op: "unloop", Subject: pathOf(loop)}
+		if len(bubbles) > 1 {
+			// bubbles looped together are in a loop of their parent's color inside it
+			undo = Step{Op: "unloop", Subject: pathOf(loop.Parent), With: [][]int{pathOf(loop)}}
+		}
+	case "unit":
+		unit := pg.NewBubble(subject.X, subject.Y, "", subject.Kind)
+		pg.Execute(func() {
+			pg.Grab(unit, subject.X, subject.Y)
+			pg.ReleaseInto(subject)
+		})
+		undo = Step{Op: "remove", Subject: pathOf(unit)}
+	case "assume":
+		// assume the variable, the same way as typing it into the assumption
+		pg.Assume(subject, subject.Parent, subject.X, subject.Y, subject.X, subject.Y)
+		positive, negative := pg.AssumptionPair.Positive, pg.AssumptionPair.Negative
+		if s.Variable != "" {
+			pg.Select(positive)
+			pg.Execute(func() {
+				pg.Grab(pg.NewBubble(positive.X, positive.Y, s.Variable, WHITE), positive.X, positive.Y)
+				pg.ReleaseInto(positive)
+			})
+		}
+		pg.ExitAssumptionMode()
+		pg.recordStep()
+		undo = Step{Op: "remove", Subject: pathOf(positive), With: [][]int{pathOf(negative)}}
+	case "delete":
+		parent, children := subject.Parent, append([]*Bubble{}, subject.Children...)
+		pg.Select(subject)
+		pg.Execute(pg.DeleteLoops)
+		if len(children) == 0 {
+			undo = Step{Op: "put", Subject: pathOf(parent), Restore: saveBubble(subject)}
+		} else {
+			// the loop is deleted, and what was in it is left behind
+			undo = Step{Op: "wrap", Restore: &savedBubble{ID: subject.ID, Kind: subject.Kind.String(), X: subject.X, Y: subject.Y}}
+			undo.Subject, undo.With = paths(children...)
+		}
+	case "copy":
+		// unlike copying in the editor, this leaves the clipboard alone
+		pg.Execute(func() { pg.pasteCopies(subject.Parent, []*Bubble{subject}, subject.X, subject.Y) })
+		undo = Step{Op: "remove"}
+		undo.Subject, undo.With = paths(pg.Highlighted...)
+	case "cut":
+		parent := subject.Parent
+		pg.Execute(func() { pg.Delete(subject) })
+		undo = Step{Op: "put", Subject: pathOf(parent), Restore: saveBubble(subject)}
+	}
+	pg.Highlighted = nil
+	return undo, nil
+}
+
+// checkUndo returns why a step returned by TakeStep can't be undone, or nil
+// if it can
+func (pg *Page) checkUndo(s Step) error {
+	if pg.Mode != ProofMode {
+		return fmt.Errorf("steps can only be undone while proving, not in %v mode", pg.Mode)
+	}
+	bubbles, err := pg.subjects(s)
+	if err != nil {
+		return err
+	}
+	subject := bubbles[0]
+
+	switch s.Op {
+	case "unmove":
+		if _, ok := follow(pg.Root, s.Target); !ok {
+			return fmt.Errorf("there's no bubble at %v", s.Target)
+		}
+		return nil
+	case "unloop":
+		for _, b := range bubbles {
+			if b.Variable != "" {
+				return reject(b, "a variable isn't a loop")
+			}
+		}
+		return nil
+	case "remove":
+		return nil
+	case "put", "wrap", "restore":
+		if s.Restore == nil {
+			return fmt.Errorf("there's nothing to %v", s.Op)
+		}
+		if s.Op == "wrap" {
+			for _, b := range bubbles {
+				if b.Parent != subject.Parent {
+					return reject(b, "only bubbles next to each other can be wrapped in a loop")
+				}
+			}
+		}
+		_, err := s.Restore.bubble()
+		return err
+	}
+	return fmt.Errorf("%q doesn't undo a step", s.Op)
+}
+
+// undo takes a step returned by TakeStep, and goes back to where the
+// statement was last the same in the history. It's refused, leaving the
+// statement alone, if that doesn't take it back to somewhere the proof has
+// already been.
+func (pg *Page) undo(s Step) error {
+	if err := pg.checkUndo(s); err != nil {
+		return err
+	}
+	bubbles, _ := pg.subjects(s)
+	subject := bubbles[0]
+	before := clone(pg.Root)
+
+	switch s.Op {
+	case "unmove":
+		target, _ := follow(pg.Root, s.Target)
+		subject.Parent.Detach(subject)
+		target.Insert(subject)
+		pg.unloop(bubbles[1:]...)
+	case "unloop":
+		pg.unloop(bubbles...)
+	case "remove":
+		for _, b := range bubbles {
+			b.Parent.Detach(b)
+		}
+	case "put":
+		b, _ := s.Restore.bubble()
+		subject.Insert(b)
+	case "wrap":
+		loop, _ := s.Restore.bubble()
+		parent := subject.Parent
+		for _, b := range bubbles {
+			parent.Detach(b)
+			loop.Insert(b)
+		}
+		parent.Insert(loop)
+	case "restore":
+		restored, _ := s.Restore.bubble()
+		pg.replace(subject, restored)
+	}
+	pg.Highlighted = nil
+	if !pg.undone() {
+		pg.setRoot(before)
+		return fmt.Errorf("undoing with %v doesn't go back to a statement in the proof", s)
+	}
+	return nil
+}
+
+// unloop takes loops away, leaving what was inside them in their place
+func (pg *Page) unloop(loops ...*Bubble) {
+	for _, loop := range loops {
+		parent := loop.Parent
+		for _, child := range append([]*Bubble{}, loop.Children...) {
+			loop.Detach(child)
+			parent.Insert(child)
+		}
+		parent.Detach(loop)
+	}
+}
+
+// undone tidies up after a step is undone, and goes back to where the
+// statement was last the same in the history, with everything where it was
+// then. It returns false if the history never had the statement in it.
+func (pg *Page) undone() bool {
+	pg.Root.normalizeDepth()
+	pg.NormalizeHeight()
+	pg.reindex()
+	for i := len(pg.History) - 1; i >= 0; i-- {
+		if pg.History[i].SameAs(pg.Root) {
+			pg.History = pg.History[:i+1]
+			pg.setRoot(pg.History[i])
+			return true
+		}
+	}
+	return false
+}
+
+// replace puts another bubble where b is
+func (pg *Page) replace(b, with *Bubble) {
+	if b == pg.Root {
+		pg.setRoot(with)
+		return
+	}
+	parent := b.Parent
+	parent.Detach(b)
+	parent.Insert(with)
+	with.normalizeDepth()
+	pg.NormalizeHeight()
+	pg.reindex()
+}
+
+// StepBetween finds a step that turns one statement into another, if there is
+// one. Assumptions are only looked for among the variables in the statements.
+func StepBetween(before, after *Bubble) (Step, bool) {
+	variables := map[string]bool{}
+	var names []string
+	for _, b := range []*Bubble{before, after} {
+		b.Iterate(func(bub *Bubble) {
+			if bub.Variable != "" && !variables[bub.Variable] {
+				variables[bub.Variable] = true
+				names = append(names, bub.Variable)
+			}
+		})
+	}
+
+	pg := NewPage(nil)
+	pg.setRoot(before)
+	pg.Mode = ProofMode
+	pg.History = []*Bubble{clone(pg.Root)}
+	for _, s := range pg.LegalSteps(names...) {
+		undo, err := pg.TakeStep(s)
+		if err != nil {
+			continue
+		}
+		if pg.Root.SameAs(after) {
+			return s, true
+		}
+		pg.undo(undo)
+	}
+	return Step{}, false
+}
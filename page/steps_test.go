@@ -0,0 +1,155 @@
+package page
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestLegalSteps(t *testing.T) {
+	pg, err := NewProofPage(nil, "(A * ~A)")
+	assert.NilError(t, err)
+	ops := map[string]int{}
+	for _, s := range pg.LegalSteps("B") {
+		assert.NilError(t, pg.CheckStep(s), "%v", s)
+		ops[s.Op]++
+	}
+	assert.Assert(t, ops["move"] > 0)
+	assert.Assert(t, ops["loop"] > 0)
+	assert.Assert(t, ops["delete"] > 0)
+	assert.Equal(t, ops["copy"], 0)
+
+	// listing steps doesn't change anything
+	assert.Equal(t, pg.Root.Tolestra(), "(A * ~A)")
+	assert.Equal(t, len(pg.History), 1)
+
+	pg.SetMode(CreateMode)
+	assert.Equal(t, len(pg.LegalSteps()), 0)
+	assert.ErrorContains(t, pg.CheckStep(Step{Op: "unit", Subject: []int{0}}), "only be taken while proving")
+}
+
+func TestUndoStep(t *testing.T) {
+	pg, err := NewProofPage(nil, "(!A + (B * ~C))")
+	assert.NilError(t, err)
+	before := shape(pg.Root)
+	for _, s := range pg.LegalSteps("A") {
+		undo, err := pg.TakeStep(s)
+		assert.NilError(t, err, "%v", s)
+		assert.NilError(t, pg.undo(undo))
+		assert.Equal(t, shape(pg.Root), before, "undoing %v", s)
+		assert.Equal(t, len(pg.History), 1, "undoing %v", s)
+	}
+}
+
+func TestInverseSteps(t *testing.T) {
+	const formula = "(!A + (B * ~C))"
+	pg, err := NewProofPage(nil, formula)
+	assert.NilError(t, err)
+	before := shape(pg.Root)
+	ops := map[string]string{}
+	for _, s := range pg.LegalSteps("A") {
+		pg, _ := NewProofPage(nil, formula)
+		undo, err := pg.TakeStep(s)
+		assert.NilError(t, err, "%v", s)
+		ops[s.Op] = undo.Op
+
+		// undoing a step takes it back by itself, since it's refused unless
+		// it goes back to a statement in the history
+		assert.NilError(t, pg.undo(undo), "undoing %v with %v", s, undo)
+		assert.Equal(t, shape(pg.Root), before, "undoing %v with %v", s, undo)
+		assert.Equal(t, len(pg.History), 1, "undoing %v", s)
+	}
+	assert.DeepEqual(t, ops, map[string]string{
+		"move": "unmove", "loop": "unloop", "why-not": "unloop", "of-course": "unloop",
+		"unit": "remove", "assume": "remove", "copy": "remove", "delete": "wrap", "cut": "put",
+	})
+
+	// a variable dropped onto a variable of the other color is taken back out
+	// of the loop put around that
+	pg = NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	add(w, 0, 0, "A", WHITE)
+	add(add(w, 0, 0, "", BLACK), 0, 0, "C", WHITE)
+	assert.NilError(t, pg.SetMode(ProofMode))
+	before = shape(pg.Root)
+	undo, err := pg.TakeStep(Step{Op: "move", Subject: []int{0, 0}, Target: []int{0, 1, 0}})
+	assert.NilError(t, err)
+	assert.Equal(t, undo.String(), "unmove [0 0 0 1] [0 0 0] -> [0]")
+	assert.NilError(t, pg.undo(undo))
+	assert.Equal(t, shape(pg.Root), before)
+
+	// bubbles which annihilate each other are restored along with everything else
+	pg, err = NewProofPage(nil, "(A * (B + ~A))")
+	assert.NilError(t, err)
+	undo, err = pg.TakeStep(Step{Op: "move", Subject: []int{0, 0, 0}, Target: []int{0, 0, 1, 1}})
+	assert.NilError(t, err)
+	assert.Equal(t, undo.Op, "restore")
+}
+
+func TestForgedUndo(t *testing.T) {
+	pg, err := NewProofPage(nil, "(A * B)")
+	assert.NilError(t, err)
+	before := shape(pg.Root)
+	forged, err := ParseTolestra("(A * ~B)")
+	assert.NilError(t, err)
+
+	// the steps which undo others can't be taken as steps of their own
+	for _, s := range []Step{
+		{Op: "restore", Subject: []int{}, Restore: saveBubble(forged[0])},
+		{Op: "remove", Subject: []int{0, 0}},
+		{Op: "put", Subject: []int{0}, Restore: saveBubble(forged[0])},
+		{Op: "unloop", Subject: []int{0}},
+	} {
+		assert.ErrorContains(t, pg.CheckStep(s), "only undoes a step", "%v", s)
+		_, err := pg.TakeStep(s)
+		assert.ErrorContains(t, err, "only undoes a step", "%v", s)
+
+		// and they can't undo a step that was never taken
+		assert.Assert(t, pg.undo(s) != nil, "%v", s)
+		assert.Equal(t, shape(pg.Root), before, "%v", s)
+		assert.Equal(t, len(pg.History), 1, "%v", s)
+	}
+}
+
+func TestSaveStep(t *testing.T) {
+	pg, err := NewProofPage(nil, "(A * B)")
+	assert.NilError(t, err)
+	before := shape(pg.Root)
+	s, err := ParseStep("loop [0 0 0] [0 0 1]")
+	assert.NilError(t, err)
+	undo, err := pg.TakeStep(s)
+	assert.NilError(t, err)
+	assert.Assert(t, shape(pg.Root) != before)
+
+	// steps survive being written out
+	data, err := json.Marshal([]Step{s, undo})
+	assert.NilError(t, err)
+	var saved []Step
+	assert.NilError(t, json.Unmarshal(data, &saved))
+	assert.Equal(t, saved[0].String(), "loop [0 0 0] [0 0 1]")
+
+	assert.NilError(t, pg.undo(saved[1]))
+	assert.Equal(t, shape(pg.Root), before)
+
+	_, err = pg.TakeStep(Step{Op: "move", Subject: []int{0, 0, 5}, Target: []int{0}})
+	assert.ErrorContains(t, err, "no bubble at [0 0 5]")
+	_, err = pg.TakeStep(Step{Op: "fly", Subject: []int{0, 0}})
+	assert.ErrorContains(t, err, "unknown step")
+}
+
+func TestStepBetween(t *testing.T) {
+	pg, err := NewProofPage(nil, "(A * (B + ~A))")
+	assert.NilError(t, err)
+	before := pg.Root.Copy()
+	_, err = pg.TakeStep(Step{Op: "move", Subject: []int{0, 0, 0}, Target: []int{0, 0, 1, 1}})
+	assert.NilError(t, err)
+	assert.Equal(t, pg.Root.Tolestra(), "B")
+
+	s, ok := StepBetween(before, pg.Root)
+	assert.Assert(t, ok)
+	assert.Equal(t, s.Op, "move")
+
+	_, ok = StepBetween(pg.Root, before)
+	assert.Assert(t, !ok)
+}
@@ -0,0 +1,219 @@
+package page
+
+import (
+	"image/color"
+	"strings"
+)
+
+// Theme is the colors a page is drawn in. Which kind a bubble is doesn't
+// depend on its color, so any theme can show any statement.
+type Theme struct {
+	Name string
+	// the color of each kind of bubble
+	Kinds map[Kind]color.Color
+	// the color of each kind of bubble where it's selected, every other pixel
+	Highlights map[Kind]color.Color
+	// what's behind the sidebar, and everything drawn on it
+	Backdrop color.Color
+	Text     color.Color
+	Link     color.Color
+	// the outline of where a grabbed bubble would be dropped, if it's allowed there or not
+	Allowed  color.Color
+	Rejected color.Color
+	// the circle around a bubble something's wrong with, and the outline of a selection being dragged out
+	Marked    color.Color
+	Selection color.Color
+}
+
+func rgb(r, g, b uint8) color.Color {
+	return color.RGBA{r, g, b, 255}
+}
+
+// Themes are the themes to choose from. The first one is the default.
+var Themes = []*Theme{
+	{
+		Name: "dark",
+		Kinds: map[Kind]color.Color{
+			WHITE:      color.White,
+			BLACK:      color.Black,
+			BLUE:       rgb(17, 205, 205),
+			RED:        rgb(238, 50, 50),
+			BACKGROUND: rgb(151, 151, 184),
+		},
+		Highlights: map[Kind]color.Color{
+			WHITE: rgb(255, 255, 100),
+			BLACK: rgb(50, 0, 135),
+			BLUE:  rgb(17, 151, 205),
+			RED:   rgb(238, 104, 50),
+		},
+		Backdrop:  color.Black,
+		Text:      rgb(200, 200, 200),
+		Link:      rgb(140, 191, 255),
+		Allowed:   rgb(77, 230, 77),
+		Rejected:  rgb(242, 64, 51),
+		Marked:    rgb(255, 217, 0),
+		Selection: rgb(255, 255, 102),
+	},
+	{
+		Name: "light",
+		Kinds: map[Kind]color.Color{
+			WHITE:      color.White,
+			BLACK:      rgb(60, 60, 75),
+			BLUE:       rgb(70, 175, 205),
+			RED:        rgb(220, 85, 85),
+			BACKGROUND: rgb(210, 210, 225),
+		},
+		Highlights: map[Kind]color.Color{
+			WHITE: rgb(255, 240, 140),
+			BLACK: rgb(95, 70, 150),
+			BLUE:  rgb(90, 140, 210),
+			RED:   rgb(235, 130, 90),
+		},
+		Backdrop:  rgb(240, 240, 245),
+		Text:      rgb(40, 40, 50),
+		Link:      rgb(30, 90, 200),
+		Allowed:   rgb(30, 150, 30),
+		Rejected:  rgb(200, 40, 30),
+		Marked:    rgb(200, 140, 0),
+		Selection: rgb(200, 160, 0),
+	},
+	{
+		// the Okabe-Ito colors, which can be told apart with any kind of color blindness
+		Name: "colorblind",
+		Kinds: map[Kind]color.Color{
+			WHITE:      color.White,
+			BLACK:      color.Black,
+			BLUE:       rgb(86, 180, 233),
+			RED:        rgb(230, 159, 0),
+			BACKGROUND: rgb(153, 153, 153),
+		},
+		Highlights: map[Kind]color.Color{
+			WHITE: rgb(240, 228, 66),
+			BLACK: rgb(0, 75, 115),
+			BLUE:  rgb(0, 114, 178),
+			RED:   rgb(213, 94, 0),
+		},
+		Backdrop:  color.Black,
+		Text:      rgb(200, 200, 200),
+		Link:      rgb(86, 180, 233),
+		Allowed:   rgb(86, 180, 233),
+		Rejected:  rgb(213, 94, 0),
+		Marked:    rgb(240, 228, 66),
+		Selection: rgb(240, 228, 66),
+	},
+	{
+		Name: "high-contrast",
+		Kinds: map[Kind]color.Color{
+			WHITE:      color.White,
+			BLACK:      color.Black,
+			BLUE:       rgb(0, 200, 255),
+			RED:        rgb(255, 0, 0),
+			BACKGROUND: rgb(128, 128, 128),
+		},
+		Highlights: map[Kind]color.Color{
+			WHITE: rgb(255, 255, 0),
+			BLACK: rgb(0, 0, 200),
+			BLUE:  rgb(0, 90, 255),
+			RED:   rgb(255, 140, 0),
+		},
+		Backdrop:  color.Black,
+		Text:      color.White,
+		Link:      rgb(0, 255, 255),
+		Allowed:   rgb(0, 255, 0),
+		Rejected:  rgb(255, 0, 255),
+		Marked:    rgb(255, 255, 0),
+		Selection: rgb(255, 255, 0),
+	},
+	{
+		// pale loops and a white backdrop, to save ink
+		Name: "print",
+		Kinds: map[Kind]color.Color{
+			WHITE:      color.White,
+			BLACK:      color.Black,
+			BLUE:       rgb(160, 210, 240),
+			RED:        rgb(245, 170, 170),
+			BACKGROUND: rgb(230, 230, 230),
+		},
+		Highlights: map[Kind]color.Color{
+			WHITE: rgb(255, 245, 170),
+			BLACK: rgb(70, 70, 70),
+			BLUE:  rgb(120, 180, 230),
+			RED:   rgb(235, 130, 130),
+		},
+		Backdrop:  color.White,
+		Text:      color.Black,
+		Link:      rgb(0, 60, 180),
+		Allowed:   rgb(0, 140, 0),
+		Rejected:  rgb(200, 0, 0),
+		Marked:    rgb(200, 130, 0),
+		Selection: rgb(200, 130, 0),
+	},
+}
+
+// DefaultTheme is the theme pages are drawn in unless another one is chosen
+var DefaultTheme = Themes[0]
+
+// ThemeNamed finds a theme by its name
+func ThemeNamed(name string) (*Theme, bool) {
+	for _, t := range Themes {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// ThemeNames lists the names of the themes, for messages about them
+func ThemeNames() []string {
+	var names []string
+	for _, t := range Themes {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// Next is the theme after this one, going back to the first after the last
+func (t *Theme) Next() *Theme {
+	for i, other := range Themes {
+		if other == t {
+			return Themes[(i+1)%len(Themes)]
+		}
+	}
+	return DefaultTheme
+}
+
+// Color is the color a kind of bubble is drawn in
+func (t *Theme) Color(k Kind) color.Color {
+	if clr, ok := t.Kinds[k]; ok {
+		return clr
+	}
+	return t.Backdrop
+}
+
+// Highlight is the color a kind of bubble is drawn in every other pixel where it's selected
+func (t *Theme) Highlight(k Kind) color.Color {
+	if clr, ok := t.Highlights[k]; ok {
+		return clr
+	}
+	return t.Color(k)
+}
+
+// Label is the color of a variable written on a kind of bubble: black on
+// light bubbles, and white on dark ones
+func (t *Theme) Label(k Kind) color.Color {
+	r, g, b, _ := t.Color(k).RGBA()
+	if 299*r+587*g+114*b > 1000*0x7fff {
+		return color.Black
+	}
+	return color.White
+}
+
+// Palette is every color the theme draws a page in, for images which can
+// only have a few colors
+func (t *Theme) Palette() color.Palette {
+	p := color.Palette{color.Black, color.White, t.Backdrop, t.Text}
+	for _, k := range Kinds {
+		p = append(p, t.Color(k), t.Highlight(k))
+	}
+	return p
+}
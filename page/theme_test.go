@@ -0,0 +1,49 @@
+package page
+
+import (
+	"image/color"
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestThemes(t *testing.T) {
+	for _, theme := range Themes {
+		t.Run(theme.Name, func(t *testing.T) {
+			for _, k := range Kinds {
+				assert.Assert(t, theme.Kinds[k] != nil, "%v has no color for %v", theme.Name, k)
+			}
+			assert.Equal(t, theme.Label(WHITE), color.Color(color.Black))
+			assert.Equal(t, theme.Label(BLACK), color.Color(color.White))
+
+			pg := NewPage(nil)
+			pg.Theme = theme
+			w := add(pg.Root, 600, 360, "", WHITE)
+			blue := add(w, 480, 360, "", BLUE)
+			a := add(blue, 480, 360, "A", WHITE)
+			b := add(w, 720, 360, "", BLACK)
+			red := add(b, 720, 360, "", RED)
+			add(red, 720, 360, "B", BLACK)
+			pg.Highlighted = []*Bubble{a, b}
+			pg.NormalizeHeight()
+			checkGolden(t, pg.RenderImage(), filepath.Join("testdata", "theme_"+theme.Name+".png"))
+		})
+	}
+
+	found, ok := ThemeNamed("High-Contrast")
+	assert.Assert(t, ok)
+	assert.Equal(t, found.Next(), Themes[4])
+	assert.Equal(t, Themes[len(Themes)-1].Next(), DefaultTheme)
+	_, ok = ThemeNamed("sepia")
+	assert.Assert(t, !ok)
+}
+
+func TestWorkspaceTheme(t *testing.T) {
+	ws, err := NewWorkspace(nil)
+	assert.NilError(t, err)
+	light, _ := ThemeNamed("light")
+	ws.SetTheme(light)
+	assert.Equal(t, ws.Page().Theme, light)
+	assert.Equal(t, ws.New().Theme, light)
+}
@@ -0,0 +1,306 @@
+package page
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// formula is a parsed linear logic formula, before it's turned into bubbles
+type formula struct {
+	op   rune // 'a' for an atom, '1', '0', '*', '+', '!' or '?'
+	neg  bool // only for atoms
+	name string
+	args []*formula
+}
+
+// dual is the negation of a formula, pushed all the way down to the atoms
+func (f *formula) dual() *formula {
+	d := &formula{name: f.name, neg: !f.neg}
+	switch f.op {
+	case 'a':
+		d.op = 'a'
+	case '1':
+		d.op = '0'
+	case '0':
+		d.op = '1'
+	case '*':
+		d.op = '+'
+	case '+':
+		d.op = '*'
+	case '!':
+		d.op = '?'
+	case '?':
+		d.op = '!'
+	}
+	for _, arg := range f.args {
+		d.args = append(d.args, arg.dual())
+	}
+	return d
+}
+
+// ParseTolestra reads formulas written in the notation used by Tolestra, with
+// several formulas separated by commas. Negation can be written in front of
+// any formula, and "-o" is linear implication. The usual unicode symbols for
+// the connectives are also understood, so formulas can be pasted from other
+// tools. The formulas are returned as bubbles ready to be placed into a white
+// bubble, and are laid out around (0, 0).
+func ParseTolestra(s string) ([]*Bubble, error) {
+	formulas, err := parseFormulas(s)
+	if err != nil {
+		return nil, err
+	}
+	bubbles := make([]*Bubble, 0, len(formulas))
+	for _, f := range formulas {
+		b := f.bubble(WHITE)
+		layout(b, 0, 0)
+		bubbles = append(bubbles, b)
+	}
+	return bubbles, nil
+}
+
+// parseFormulas reads formulas separated by commas
+func parseFormulas(s string) ([]*formula, error) {
+	p := &parser{tokens: tokenize(s)}
+	var formulas []*formula
+	for {
+		f, err := p.implication()
+		if err != nil {
+			return nil, err
+		}
+		formulas = append(formulas, f)
+		if !p.accept(",") {
+			break
+		}
+	}
+	if p.pos < len(p.tokens) {
+		return nil, p.unexpected()
+	}
+	return formulas, nil
+}
+
+// bubble turns a formula into bubbles, for placing into a bubble of the given kind.
+// Variables are always in a bubble of their own color, like ReleaseInto does.
+func (f *formula) bubble(parent Kind) *Bubble {
+	var b *Bubble
+	kids := f.args
+	switch f.op {
+	case 'a':
+		kind := WHITE
+		if f.neg {
+			kind = BLACK
+		}
+		b = newBubble(0, 0, f.name, kind)
+		if parent.Polarity() != kind {
+			loop := newBubble(0, 0, "", kind)
+			loop.Insert(b)
+			b = loop
+		}
+		return b
+	case '1':
+		return newBubble(0, 0, "", WHITE)
+	case '0':
+		return newBubble(0, 0, "", BLACK)
+	case '*':
+		b = newBubble(0, 0, "", WHITE)
+	case '+':
+		b = newBubble(0, 0, "", BLACK)
+	case '!':
+		b = newBubble(0, 0, "", BLUE)
+		if kids[0].op == '*' {
+			kids = kids[0].args
+		}
+	case '?':
+		b = newBubble(0, 0, "", RED)
+		if kids[0].op == '+' {
+			kids = kids[0].args
+		}
+	}
+	for _, kid := range kids {
+		b.Insert(kid.bubble(b.Kind))
+	}
+	return b
+}
+
+// how far apart the variables of parsed formulas are spread
+const layoutSpacing = 80
+
+// layout spreads the leaves of a bubble out in a row centered on (x, y), with
+// each bubble centered above its leaves
+func layout(b *Bubble, x, y int) {
+	leaves := 0
+	b.Iterate(func(bub *Bubble) {
+		if len(bub.Children) == 0 {
+			leaves++
+		}
+	})
+	left := x - (leaves-1)*layoutSpacing/2
+	var place func(b *Bubble)
+	place = func(b *Bubble) {
+		if len(b.Children) == 0 {
+			b.X, b.Y = left, y
+			left += layoutSpacing
+			return
+		}
+		for _, child := range b.Children {
+			place(child)
+		}
+		b.CenterAroundChildren()
+	}
+	place(b)
+}
+
+func tokenize(s string) []string {
+	var tokens []string
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+		case r == '-' && i+1 < len(runes) && runes[i+1] == 'o':
+			tokens = append(tokens, "-o")
+			i++
+		case r == '⊸':
+			tokens = append(tokens, "-o")
+		case strings.ContainsRune("(),*+~!?", r):
+			tokens = append(tokens, string(r))
+		case r == '⊗':
+			tokens = append(tokens, "*")
+		case r == '⅋':
+			tokens = append(tokens, "+")
+		case r == '¬':
+			tokens = append(tokens, "~")
+		case r == '⊥':
+			tokens = append(tokens, "0")
+		default:
+			// anything else is part of a variable name
+			j := i
+			for j < len(runes) && isNameRune(runes[j]) {
+				j++
+			}
+			if j == i {
+				j++
+			}
+			tokens = append(tokens, string(runes[i:j]))
+			i = j - 1
+		}
+	}
+	return tokens
+}
+
+func isNameRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '\''
+}
+
+// isName returns whether a variable can be called name, so that it's read
+// back as that variable rather than as a unit or as more than one token
+func isName(name string) bool {
+	if name == "" || name == "1" || name == "0" {
+		return false
+	}
+	for _, r := range name {
+		if !isNameRune(r) {
+			return false
+		}
+	}
+	return true
+}
+
+type parser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *parser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *parser) accept(token string) bool {
+	if p.peek() == token {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) unexpected() error {
+	if p.pos >= len(p.tokens) {
+		return fmt.Errorf("unexpected end of formula")
+	}
+	return fmt.Errorf("unexpected %q in formula", p.tokens[p.pos])
+}
+
+// implication := par ["-o" implication]
+func (p *parser) implication() (*formula, error) {
+	f, err := p.connective("+", p.tensor)
+	if err != nil || !p.accept("-o") {
+		return f, err
+	}
+	g, err := p.implication()
+	if err != nil {
+		return nil, err
+	}
+	return &formula{op: '+', args: []*formula{f.dual(), g}}, nil
+}
+
+func (p *parser) tensor() (*formula, error) {
+	return p.connective("*", p.unary)
+}
+
+// connective parses operands separated by a connective, which may be omitted
+// when there's only one
+func (p *parser) connective(op string, operand func() (*formula, error)) (*formula, error) {
+	f, err := operand()
+	if err != nil || p.peek() != op {
+		return f, err
+	}
+	args := []*formula{f}
+	for p.accept(op) {
+		g, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, g)
+	}
+	return &formula{op: rune(op[0]), args: args}, nil
+}
+
+// unary := ("~" | "!" | "?") unary | "(" implication ")" | unit | atom
+func (p *parser) unary() (*formula, error) {
+	switch token := p.peek(); token {
+	case "~", "!", "?":
+		p.pos++
+		f, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if token == "~" {
+			return f.dual(), nil
+		}
+		return &formula{op: rune(token[0]), args: []*formula{f}}, nil
+	case "(":
+		p.pos++
+		f, err := p.implication()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.unexpected()
+		}
+		return f, nil
+	case "1", "0":
+		p.pos++
+		return &formula{op: rune(token[0])}, nil
+	case "":
+		return nil, p.unexpected()
+	default:
+		if !isNameRune([]rune(token)[0]) {
+			return nil, p.unexpected()
+		}
+		p.pos++
+		return &formula{op: 'a', name: token}, nil
+	}
+}
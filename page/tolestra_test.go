@@ -0,0 +1,92 @@
+package page
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestParseTolestra(t *testing.T) {
+	cases := []struct {
+		in, out string
+	}{
+		{"A", "A"},
+		{"~A", "~A"},
+		{"1", "1"},
+		{"0", "0"},
+		{"(A * B)", "(A * B)"},
+		{"(A + ~A)", "(A + ~A)"},
+		{"!(A * B)", "!(A * B)"},
+		{"?~B", "?~B"},
+		{"!1", "!1"},
+		{"?0", "?0"},
+		{"A * B + C", "((A * B) + C)"},
+		{"~(A * !B)", "(?~B + ~A)"},
+		{"~~A", "A"},
+		{"A -o B -o C", "((C + ~B) + ~A)"},
+		{"A ⊗ B ⅋ ⊥", "((A * B) + 0)"},
+		{"¬A' ⊸ B_1", "(A' + B_1)"},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			bubbles, err := ParseTolestra(c.in)
+			assert.NilError(t, err)
+			assert.Equal(t, len(bubbles), 1)
+			assert.Equal(t, bubbles[0].Tolestra(), c.out)
+		})
+	}
+}
+
+func TestParseTolestraErrors(t *testing.T) {
+	for _, in := range []string{"", "(A * B", "A * ", "A B", "A )", "A, "} {
+		_, err := ParseTolestra(in)
+		assert.Assert(t, err != nil, in)
+	}
+}
+
+func TestParseTolestraVariables(t *testing.T) {
+	// a variable always ends up in a bubble of its own color
+	bubbles, err := ParseTolestra("(~A + B), C")
+	assert.NilError(t, err)
+	assert.Equal(t, len(bubbles), 2)
+	par := bubbles[0]
+	assert.Equal(t, par.Kind, BLACK)
+	par.Iterate(func(b *Bubble) {
+		if b.Variable != "" {
+			assert.Equal(t, b.Parent.Kind, b.Kind)
+		}
+	})
+	assert.Equal(t, bubbles[1].Variable, "C")
+}
+
+func TestEmptyLoops(t *testing.T) {
+	// empty loops are written so they can be read back
+	assert.Equal(t, newBubble(0, 0, "", BLUE).Tolestra(), "!1")
+	assert.Equal(t, newBubble(0, 0, "", RED).Tolestra(), "?0")
+	assert.Equal(t, newBubble(0, 0, "", RED).Opposite(), "!1")
+}
+
+func TestPretty(t *testing.T) {
+	cases := []struct {
+		in, out string
+	}{
+		{"A", "A"},
+		{"~A", "¬A"},
+		{"0", "⊥"},
+		{"?0", "?⊥"},
+		{"(A * B)", "(A ⊗ B)"},
+		{"!(A * ~B)", "!(A ⊗ ¬B)"},
+		{"A -o (B + ?C)", "((?C ⅋ B) ⅋ ¬A)"},
+	}
+	for _, c := range cases {
+		t.Run(c.in, func(t *testing.T) {
+			bubbles, err := ParseTolestra(c.in)
+			assert.NilError(t, err)
+			assert.Equal(t, bubbles[0].Pretty(), c.out)
+			// and it reads back as the same statement
+			again, err := ParseTolestra(bubbles[0].Pretty())
+			assert.NilError(t, err)
+			assert.Equal(t, again[0].Tolestra(), bubbles[0].Tolestra())
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package page
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"vll/eventlog"
+)
+
+// Lesson is one part of the tutorial: a statement to prove, instructions for
+// what to do with it, and the kind of step which finishes the lesson
+type Lesson struct {
+	Name      string
+	Statement string
+	// Instructions can mention an action's bindings with its name in braces, like {loop}
+	Instructions []string
+	// the kind of step the lesson is about, as in Step.Op
+	Expect string
+	// what the goal has to be after the step, or anything if it's empty
+	Goal string
+}
+
+// Lessons are the lessons of the tutorial, in order
+var Lessons = []Lesson{
+	{
+		Name:      "Moving",
+		Statement: "((A + B) * ~A)",
+		Instructions: []string{
+			"A proof turns the statement into what follows from it, one step at a time.",
+			"Drag the Black A with {grab}, and drop it next to the A inside A + B. A bubble in a White bubble can move further inside it.",
+			"Jerking a grabbed bubble pulls it out of its parent, to be dropped somewhere else. It snaps back if it isn't allowed there.",
+		},
+		Expect: "move",
+	},
+	{
+		Name:      "Annihilating",
+		Statement: "(A * (B + ~A))",
+		Instructions: []string{
+			"A bubble dropped onto its dual annihilates with it.",
+			"Drag the White A onto the Black A, inside the Black bubble, to be left with B.",
+		},
+		Expect: "move",
+		Goal:   "B",
+	},
+	{
+		Name:      "Loops",
+		Statement: "A",
+		Instructions: []string{
+			"Two loops around something mean the same as nothing at all, so they can be added anywhere.",
+			"Click A to select it, and press {loop} to wrap it in a loop of the opposite color.",
+		},
+		Expect: "loop",
+	},
+	{
+		Name:      "Units",
+		Statement: "A",
+		Instructions: []string{
+			"An empty bubble is a unit, which can be put into a bubble of the same color.",
+			"Select a bubble, and press {unit} to put a unit into it.",
+		},
+		Expect: "unit",
+	},
+	{
+		Name:      "Assumptions",
+		Statement: "(A + ~A)",
+		Instructions: []string{
+			"An assumption pair is a formula in a White bubble along with its dual in the Black bubble around it.",
+			"Hold {assume} on the White bubble around A, and let go in the Black bubble around it.",
+			"Type A to fill in the assumption, and then press {assume} outside of it to finish.",
+		},
+		Expect: "assume",
+	},
+	{
+		Name:      "Dereliction",
+		Statement: "!A",
+		Instructions: []string{
+			"A Blue loop holds as many copies of something as are needed.",
+			"Select the Blue loop, and press {delete} to take a single A out of it.",
+		},
+		Expect: "delete",
+		Goal:   "A",
+	},
+	{
+		Name:      "Contraction",
+		Statement: "!A",
+		Instructions: []string{
+			"A Blue loop can be copied, as long as the copy goes next to it.",
+			"Copy the Blue loop with {copy}, and drop the copy next to it.",
+		},
+		Expect: "copy",
+		Goal:   "(!A * !A)",
+	},
+}
+
+// Tutorial goes through the lessons, and remembers which ones have been finished
+type Tutorial struct {
+	Lessons []Lesson
+	// the lesson being done
+	Current  int
+	Finished map[string]bool
+	// where which lessons have been finished is saved
+	File string
+	// the page the current lesson is on
+	Page *Page
+	// the last step that's been checked, so that it's only checked once
+	checked *Bubble
+}
+
+// savedTutorial is how far through the tutorial is written to a file
+type savedTutorial struct {
+	Current  int      `json:"current"`
+	Finished []string `json:"finished,omitempty"`
+}
+
+// DefaultTutorialPath is where how far through the tutorial is saved
+func DefaultTutorialPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "vll", "tutorial.json"), nil
+}
+
+// LoadTutorial picks the tutorial back up where it was left, from the given
+// file. A file that doesn't exist yet starts from the beginning.
+func LoadTutorial(path string) (*Tutorial, error) {
+	t := &Tutorial{Lessons: Lessons, Finished: make(map[string]bool), File: path}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved savedTutorial
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("%v isn't a saved tutorial: %v", path, err)
+	}
+	if saved.Current >= 0 && saved.Current < len(t.Lessons) {
+		t.Current = saved.Current
+	}
+	for _, name := range saved.Finished {
+		t.Finished[name] = true
+	}
+	return t, nil
+}
+
+// Save writes which lessons have been finished to the tutorial's file
+func (t *Tutorial) Save() error {
+	saved := savedTutorial{Current: t.Current}
+	for _, lesson := range t.Lessons {
+		if t.Finished[lesson.Name] {
+			saved.Finished = append(saved.Finished, lesson.Name)
+		}
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(t.File), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(t.File, data, 0644)
+}
+
+// Lesson is the lesson being done
+func (t *Tutorial) Lesson() Lesson {
+	return t.Lessons[t.Current]
+}
+
+// Done returns whether the lesson being done has been finished
+func (t *Tutorial) Done() bool {
+	return t.Finished[t.Lesson().Name]
+}
+
+// Open shows the current lesson in a workspace, starting it over. It takes the
+// place of the page of the lesson before, if that's still open.
+func (t *Tutorial) Open(ws *Workspace) error {
+	lesson := t.Lesson()
+	pg, err := NewProofPage(ws.win, lesson.Statement)
+	if err != nil {
+		return fmt.Errorf("lesson %q: %v", lesson.Name, err)
+	}
+	pg.Name = fmt.Sprintf("Lesson %v: %v", t.Current+1, lesson.Name)
+	Log.Info("lesson", eventlog.F("name", lesson.Name), eventlog.F("action", "start"))
+	for i, old := range ws.Pages {
+		if old == t.Page {
+			pg.Theme = ws.Theme
+			ws.Pages[i] = pg
+			t.Page = pg
+			return ws.Switch(i)
+		}
+	}
+	t.Page = pg
+	ws.Add(pg)
+	return nil
+}
+
+// Next moves on to the lesson after this one, and shows it
+func (t *Tutorial) Next(ws *Workspace) error {
+	if t.Current+1 >= len(t.Lessons) {
+		return fmt.Errorf("that was the last lesson")
+	}
+	t.Current++
+	if err := t.Save(); err != nil {
+		return err
+	}
+	return t.Open(ws)
+}
+
+// Check looks at the last step taken on the lesson's page, and finishes the
+// lesson if it's the kind of step the lesson is about. It returns whether the
+// lesson was just finished.
+func (t *Tutorial) Check() (bool, error) {
+	pg := t.Page
+	if pg == nil || t.Done() || pg.Mode != ProofMode || len(pg.History) < 2 {
+		return false, nil
+	}
+	last := pg.History[len(pg.History)-1]
+	if last == t.checked {
+		return false, nil
+	}
+	t.checked = last
+	lesson := t.Lesson()
+	s, ok := StepBetween(pg.History[len(pg.History)-2], pg.History[len(pg.History)-1])
+	if !ok || s.Op != lesson.Expect {
+		return false, nil
+	}
+	if lesson.Goal != "" {
+		goal, err := NewProofPage(nil, lesson.Goal)
+		if err != nil {
+			return false, fmt.Errorf("lesson %q: %v", lesson.Name, err)
+		}
+		if goal.Root.Tolestra() != pg.Root.Tolestra() {
+			return false, nil
+		}
+	}
+	Log.Info("lesson", eventlog.F("name", lesson.Name), eventlog.F("action", "finish"))
+	t.Finished[lesson.Name] = true
+	return true, t.Save()
+}
+
+// Progress is how many lessons have been finished
+func (t *Tutorial) Progress() int {
+	n := 0
+	for _, lesson := range t.Lessons {
+		if t.Finished[lesson.Name] {
+			n++
+		}
+	}
+	return n
+}
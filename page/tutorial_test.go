@@ -0,0 +1,77 @@
+package page
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestLessons(t *testing.T) {
+	dir := t.TempDir()
+	for i, lesson := range Lessons {
+		t.Run(lesson.Name, func(t *testing.T) {
+			// every lesson can be finished with one of the steps that can be taken
+			start, err := NewProofPage(nil, lesson.Statement)
+			assert.NilError(t, err)
+			finished := false
+			for _, s := range start.LegalSteps("A") {
+				tut, err := LoadTutorial(filepath.Join(dir, lesson.Name+".json"))
+				assert.NilError(t, err)
+				tut.Current = i
+				tut.Page, _ = NewProofPage(nil, lesson.Statement)
+				_, err = tut.Page.TakeStep(s)
+				assert.NilError(t, err)
+				done, err := tut.Check()
+				assert.NilError(t, err)
+				if done {
+					assert.Equal(t, s.Op, lesson.Expect)
+					finished = true
+				}
+			}
+			assert.Assert(t, finished, "no step finishes the lesson")
+		})
+	}
+}
+
+func TestTutorial(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vll", "tutorial.json")
+	tut, err := LoadTutorial(path)
+	assert.NilError(t, err)
+	ws, err := NewWorkspace(nil)
+	assert.NilError(t, err)
+
+	assert.NilError(t, tut.Open(ws))
+	assert.Equal(t, len(ws.Pages), 2)
+	assert.Equal(t, ws.Page(), tut.Page)
+	assert.Equal(t, tut.Page.Title(), "Lesson 1: Moving")
+
+	// other kinds of steps don't finish the lesson
+	_, err = tut.Page.TakeStep(Step{Op: "unit", Subject: []int{0}})
+	assert.NilError(t, err)
+	done, err := tut.Check()
+	assert.NilError(t, err)
+	assert.Assert(t, !done)
+	assert.Equal(t, tut.Progress(), 0)
+
+	_, err = tut.Page.TakeStep(Step{Op: "move", Subject: []int{0, 0, 1}, Target: []int{0, 0, 0, 0}})
+	assert.NilError(t, err)
+	done, err = tut.Check()
+	assert.NilError(t, err)
+	assert.Assert(t, done)
+	assert.Assert(t, tut.Done())
+
+	// the next lesson takes the place of this one's page
+	ws.Switch(0)
+	assert.NilError(t, tut.Next(ws))
+	assert.Equal(t, len(ws.Pages), 2)
+	assert.Equal(t, ws.Current, 1)
+	assert.Equal(t, tut.Page.Title(), "Lesson 2: Annihilating")
+
+	// and how far through it is is picked back up
+	again, err := LoadTutorial(path)
+	assert.NilError(t, err)
+	assert.Equal(t, again.Current, 1)
+	assert.Equal(t, again.Progress(), 1)
+	assert.Assert(t, !again.Done())
+}
@@ -0,0 +1,112 @@
+package page
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InvalidTree lists everything wrong with a tree of bubbles
+type InvalidTree struct {
+	Problems []string
+}
+
+func (e *InvalidTree) Error() string {
+	return "invalid tree: " + strings.Join(e.Problems, "; ")
+}
+
+// Validate checks that the tree below b is consistent: every child points
+// back to its parent, depths and heights are right, nothing is its own
+// ancestor, no two bubbles share an ID, assumption pairs point at each other,
+// and the kinds of bubbles are nested properly. Depths are checked relative to b.
+func (b *Bubble) Validate() error {
+	v := &validator{seen: make(map[*Bubble]bool), ids: make(map[ID]*Bubble)}
+	v.check(b)
+	return v.err()
+}
+
+// Validate checks the whole statement, along with the page's assumption pair
+func (pg *Page) Validate() error {
+	v := &validator{seen: make(map[*Bubble]bool), ids: make(map[ID]*Bubble)}
+	if pg.Root.Parent != nil {
+		v.problem(pg.Root, "the root has a parent")
+	}
+	if pg.Root.Depth != 0 {
+		v.problem(pg.Root, "the root has depth %v", pg.Root.Depth)
+	}
+	if pg.Root.Kind != BACKGROUND {
+		v.problem(pg.Root, "the root isn't the background")
+	}
+	v.check(pg.Root)
+	if pair := pg.AssumptionPair; pair != nil && pair.Positive != nil && pair.Negative != nil {
+		if pair.Positive.AssumptionPair != pair.Negative || pair.Negative.AssumptionPair != pair.Positive {
+			v.problem(pair.Positive, "the assumption pair isn't linked to %v", describe(pair.Negative))
+		}
+	}
+	return v.err()
+}
+
+type validator struct {
+	seen     map[*Bubble]bool
+	ids      map[ID]*Bubble
+	problems []string
+}
+
+func (v *validator) problem(b *Bubble, format string, args ...interface{}) {
+	v.problems = append(v.problems, describe(b)+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &InvalidTree{Problems: v.problems}
+}
+
+func (v *validator) check(b *Bubble) {
+	if v.seen[b] {
+		v.problem(b, "appears twice, so the tree has a cycle")
+		return
+	}
+	v.seen[b] = true
+	if other, ok := v.ids[b.ID]; ok {
+		v.problem(b, "has the same ID as %v", describe(other))
+	}
+	v.ids[b.ID] = b
+
+	if pair := b.AssumptionPair; pair != nil && pair.AssumptionPair != b {
+		v.problem(b, "its assumption pair %v isn't paired with it", describe(pair))
+	}
+	switch b.Kind {
+	case WHITE, BLACK:
+	case BLUE, RED:
+		if b.Variable != "" {
+			v.problem(b, "loops can't have variables")
+		}
+	case BACKGROUND:
+		if b.Parent != nil {
+			v.problem(b, "the background can only be the root")
+		}
+	default:
+		v.problem(b, "unknown kind")
+	}
+	if b.Variable != "" && len(b.Children) > 0 {
+		v.problem(b, "variables can't have anything inside them")
+	}
+
+	height := 0
+	for _, child := range b.Children {
+		if child.Parent != b {
+			v.problem(child, "is a child of %v, but its parent is %v", describe(b), describe(child.Parent))
+		}
+		if child.Depth != b.Depth+1 {
+			v.problem(child, "has depth %v inside %v with depth %v", child.Depth, describe(b), b.Depth)
+		}
+		v.check(child)
+		if child.Height+1 > height {
+			height = child.Height + 1
+		}
+	}
+	if b.Height != height {
+		v.problem(b, "has height %v, not %v", b.Height, height)
+	}
+}
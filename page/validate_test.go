@@ -0,0 +1,77 @@
+package page
+
+import (
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+// every test checks the statement after everything that changes it
+func init() {
+	validateAfterExecute = true
+}
+
+func TestValidate(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	a := add(w, 0, 0, "A", WHITE)
+	b := add(w, 0, 0, "", BLACK)
+	assert.NilError(t, pg.Validate())
+
+	a.Depth = 5
+	assert.ErrorContains(t, pg.Validate(), "has depth 5")
+	a.Depth = 2
+
+	b.Parent = pg.Root
+	assert.ErrorContains(t, pg.Validate(), "but its parent is Root")
+	b.Parent = w
+
+	w.Height = 0
+	assert.ErrorContains(t, pg.Validate(), "has height 0, not 1")
+	w.Height = 1
+
+	b.Kind = BACKGROUND
+	assert.ErrorContains(t, pg.Validate(), "the background can only be the root")
+	b.Kind = BLACK
+
+	unit := add(a, 0, 0, "", WHITE)
+	assert.ErrorContains(t, pg.Validate(), "variables can't have anything inside them")
+	a.Detach(unit)
+
+	b.AssumptionPair = a
+	assert.ErrorContains(t, pg.Validate(), "isn't paired with it")
+	a.AssumptionPair = b
+	assert.NilError(t, pg.Validate())
+	a.AssumptionPair, b.AssumptionPair = nil, nil
+
+	id := b.ID
+	b.ID = a.ID
+	assert.ErrorContains(t, pg.Validate(), "has the same ID as")
+	b.ID = id
+
+	// a cycle can only be made by going around Insert
+	b.Children = append(b.Children, w)
+	assert.ErrorContains(t, w.Validate(), "the tree has a cycle")
+}
+
+func TestInsertKeepsTreeValid(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	b := add(w, 0, 0, "", BLACK)
+	inner := add(b, 0, 0, "", WHITE)
+	a := add(inner, 0, 0, "A", WHITE)
+	assert.NilError(t, pg.Validate())
+
+	// moving a subtree fixes the depths inside it, and the heights above it
+	w.Detach(inner)
+	assert.NilError(t, inner.Validate())
+	assert.Equal(t, a.Depth, 1)
+	assert.Equal(t, w.Height, 1)
+	pg.Root.Insert(inner)
+	assert.Equal(t, a.Depth, 2)
+	assert.NilError(t, pg.Validate())
+
+	// cycles are refused
+	assert.Assert(t, a.Insert(inner) == nil)
+	assert.NilError(t, pg.Validate())
+}
@@ -0,0 +1,204 @@
+package page
+
+import (
+	"errors"
+	"fmt"
+	"vll/eventlog"
+
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// Workspace is every page open at once, like a theorem along with scratch
+// pages for the lemmas it needs. Each page has its own mode and history.
+type Workspace struct {
+	Pages []*Page
+	// the index of the page being shown
+	Current int
+	win     *pixelgl.Window
+	// the colors every page is drawn in
+	Theme *Theme
+	// how many pages have been named, so that names aren't reused
+	named int
+}
+
+// NewWorkspace opens a page for each of the given files, or starts with one
+// empty page if there aren't any
+func NewWorkspace(win *pixelgl.Window, paths ...string) (*Workspace, error) {
+	ws := &Workspace{win: win, Theme: DefaultTheme}
+	for _, path := range paths {
+		if _, err := ws.Open(path); err != nil {
+			return nil, err
+		}
+	}
+	if len(ws.Pages) == 0 {
+		ws.New()
+	}
+	ws.Current = 0
+	return ws, nil
+}
+
+// Page is the page being shown
+func (ws *Workspace) Page() *Page {
+	return ws.Pages[ws.Current]
+}
+
+// Add adds a page after the others, and shows it
+func (ws *Workspace) Add(pg *Page) {
+	if pg.Name == "" {
+		ws.named++
+		pg.Name = fmt.Sprintf("Page %v", ws.named)
+	}
+	pg.Theme = ws.Theme
+	ws.Pages = append(ws.Pages, pg)
+	ws.Current = len(ws.Pages) - 1
+	Log.Info("page", eventlog.F("action", "add"), eventlog.F("title", pg.Title()))
+}
+
+// New adds an empty page, and shows it
+func (ws *Workspace) New() *Page {
+	pg := NewPage(ws.win)
+	ws.Add(pg)
+	return pg
+}
+
+// Open adds a page saved in a file, and shows it. A file that's already open
+// is just shown.
+func (ws *Workspace) Open(path string) (*Page, error) {
+	for i, pg := range ws.Pages {
+		if pg.File == path {
+			ws.Current = i
+			return pg, nil
+		}
+	}
+	pg, err := LoadPage(ws.win, path)
+	if err != nil {
+		return nil, err
+	}
+	ws.Add(pg)
+	return pg, nil
+}
+
+// Switch shows another page
+func (ws *Workspace) Switch(i int) error {
+	if i < 0 || i >= len(ws.Pages) {
+		return fmt.Errorf("there's no page %v", i+1)
+	}
+	ws.Current = i
+	Log.Info("page", eventlog.F("action", "switch"), eventlog.F("title", ws.Page().Title()))
+	return nil
+}
+
+// SetTheme draws every page in another theme, along with any opened later
+func (ws *Workspace) SetTheme(t *Theme) {
+	ws.Theme = t
+	for _, pg := range ws.Pages {
+		pg.Theme = t
+	}
+	Log.Info("theme", eventlog.F("name", t.Name))
+}
+
+// Close removes a page. The last page can't be closed.
+func (ws *Workspace) Close(i int) error {
+	if i < 0 || i >= len(ws.Pages) {
+		return fmt.Errorf("there's no page %v", i+1)
+	}
+	if len(ws.Pages) == 1 {
+		return errors.New("the last page can't be closed")
+	}
+	Log.Info("page", eventlog.F("action", "close"), eventlog.F("title", ws.Pages[i].Title()))
+	ws.Pages = append(ws.Pages[:i], ws.Pages[i+1:]...)
+	if ws.Current > i || ws.Current == len(ws.Pages) {
+		ws.Current--
+	}
+	return nil
+}
+
+// Lemma is the statement proved on the page, which can be used on other
+// pages. Only statements proved from nothing but units can be, since every
+// step of a proof follows from the statement it started with, and the proof
+// is checked before the statement is handed out.
+func (pg *Page) Lemma() ([]*Bubble, error) {
+	if pg.Mode != ProofMode {
+		return nil, fmt.Errorf("%v isn't proved yet", pg.Title())
+	}
+	if !onlyUnits(pg.Theorem) {
+		return nil, fmt.Errorf("%v is proved from %v, not from nothing", pg.Title(), pg.Theorem.Tolestra())
+	}
+	if len(pg.Root.Children) == 0 {
+		return nil, fmt.Errorf("%v doesn't prove anything yet", pg.Title())
+	}
+	// the history could have been changed by anything, not just steps
+	if !sameSnapshots(pg.checked, pg.History) {
+		if err := pg.CheckProof(); err != nil {
+			return nil, fmt.Errorf("%v isn't proved: %v", pg.Title(), err)
+		}
+		pg.checked = append([]*Bubble{}, pg.History...)
+	}
+	return pg.Root.Children, nil
+}
+
+// sameSnapshots returns whether two histories are made of the very same snapshots
+func sameSnapshots(a, b []*Bubble) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// onlyUnits returns whether a statement is made of nothing but White and
+// Black units, which all mean the same thing
+func onlyUnits(b *Bubble) bool {
+	units := true
+	b.Iterate(func(bub *Bubble) {
+		if bub.Variable != "" || bub.Kind.IsExponential() {
+			units = false
+		}
+	})
+	return units
+}
+
+// CheckUseLemma returns why the statement proved on another page can't be
+// put into a bubble, or nil if it can. While proving, a lemma can go into any
+// White bubble, since anything proved from nothing follows from a unit.
+func (pg *Page) CheckUseLemma(from *Page, parent *Bubble) error {
+	if from == pg {
+		return errors.New("a lemma has to be used on another page")
+	}
+	if _, err := from.Lemma(); err != nil {
+		return err
+	}
+	parent = pasteTarget(parent)
+	switch pg.Mode {
+	case ProofMode:
+		if parent.Kind != WHITE && parent != pg.Root {
+			return reject(parent, "a lemma can only be used in a White bubble")
+		}
+	case ContingencyMode:
+		if parent != pg.Contingency && !pg.InContingency(parent) {
+			return reject(parent, "a lemma can only be used inside the contingency")
+		}
+	case AssumptionMode:
+		return errors.New("can't use a lemma while making an assumption")
+	}
+	return nil
+}
+
+// UseLemma puts a copy of the statement proved on another page into a
+// bubble, centered on (x, y), and selects it
+func (pg *Page) UseLemma(from *Page, parent *Bubble, x, y int) error {
+	if err := pg.CheckUseLemma(from, parent); err != nil {
+		Log.Info("lemma", eventlog.F("from", from.Title()), Field("into", parent), eventlog.F("result", "rejected"), eventlog.F("reason", err))
+		return err
+	}
+	lemma, _ := from.Lemma()
+	Log.Info("lemma", eventlog.F("from", from.Title()), Field("into", parent))
+	pg.Lemmas = append(pg.Lemmas, from.Lemmas...)
+	pg.Lemmas = append(pg.Lemmas, &Proof{Theorem: from.Theorem, History: append([]*Bubble{}, from.History...)})
+	pg.pasteCopies(parent, lemma, x, y)
+	return nil
+}
@@ -0,0 +1,153 @@
+package page
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestWorkspace(t *testing.T) {
+	ws, err := NewWorkspace(nil)
+	assert.NilError(t, err)
+	first := ws.Page()
+	assert.Equal(t, first.Title(), "Page 1")
+	assert.ErrorContains(t, ws.Close(0), "last page")
+
+	second := ws.New()
+	assert.Equal(t, second.Title(), "Page 2")
+	assert.Equal(t, ws.Page(), second)
+
+	// each page has its own mode
+	add(first.Root, 0, 0, "A", WHITE)
+	assert.NilError(t, ws.Switch(0))
+	assert.NilError(t, ws.Page().SetMode(ProofMode))
+	assert.Equal(t, second.Mode, CreateMode)
+	assert.Assert(t, ws.Switch(2) != nil)
+
+	assert.NilError(t, ws.Close(0))
+	assert.Equal(t, ws.Page(), second)
+	assert.Equal(t, ws.New().Title(), "Page 3")
+}
+
+func TestUseLemma(t *testing.T) {
+	lemma := emptyProofPage()
+	for _, str := range strings.Split("loop [0]; assume [0 0] A", "; ") {
+		s, err := ParseStep(str)
+		assert.NilError(t, err)
+		_, err = lemma.TakeStep(s)
+		assert.NilError(t, err)
+	}
+
+	pg := NewPage(nil)
+	w := add(pg.Root, 0, 0, "", WHITE)
+	add(w, 0, 0, "B", WHITE)
+	b := add(w, 100, 0, "", BLACK)
+	add(b, 100, 0, "C", BLACK)
+	assert.NilError(t, pg.SetMode(ProofMode))
+
+	assert.ErrorContains(t, pg.CheckUseLemma(pg, w), "another page")
+	assert.ErrorContains(t, pg.CheckUseLemma(lemma, b), "White bubble")
+	pg.Execute(func() { assert.NilError(t, pg.UseLemma(lemma, w, 50, 50)) })
+	assert.Equal(t, pg.Root.Tolestra(), "((A + ~A) * B * ~C)")
+	assert.Equal(t, len(pg.History), 2)
+	assert.NilError(t, pg.Validate())
+
+	// a statement proved from something else isn't a lemma
+	other := NewPage(nil)
+	add(other.Root, 0, 0, "", WHITE)
+	assert.ErrorContains(t, other.CheckUseLemma(pg, other.Root.Children[0]), "not from nothing")
+	unproved := NewPage(nil)
+	assert.ErrorContains(t, other.CheckUseLemma(unproved, other.Root), "isn't proved")
+
+	// and nor is one whose history doesn't follow from what it started with
+	forged := emptyProofPage()
+	statement, err := ParseTolestra("(A * ~B)")
+	assert.NilError(t, err)
+	forged.Root.Children[0].Insert(statement[0])
+	forged.reindex()
+	forged.recordStep()
+	assert.ErrorContains(t, other.CheckUseLemma(forged, other.Root), "doesn't follow")
+}
+
+func TestSaveLoad(t *testing.T) {
+	pg := NewPage(nil)
+	w := add(pg.Root, 10, 20, "", WHITE)
+	add(w, 30, 40, "A", WHITE)
+	add(w, 50, 60, "", BLACK)
+	pg.File = filepath.Join(t.TempDir(), "lemma.vll")
+	assert.Equal(t, pg.Title(), "lemma")
+	assert.NilError(t, pg.Save())
+
+	loaded, err := LoadPage(nil, pg.File)
+	assert.NilError(t, err)
+	assert.Equal(t, loaded.Mode, CreateMode)
+	assert.Assert(t, loaded.Root.SameAs(pg.Root))
+	assert.Equal(t, loaded.Root.Children[0].X, 10)
+	assert.NilError(t, loaded.Validate())
+
+	// a proof is picked back up where it was left
+	assert.NilError(t, pg.SetMode(ProofMode))
+	_, err = pg.TakeStep(Step{Op: "loop", Subject: pathOf(w.Children[0])})
+	assert.NilError(t, err)
+	assert.NilError(t, pg.Save())
+	loaded, err = LoadPage(nil, pg.File)
+	assert.NilError(t, err)
+	assert.Equal(t, loaded.Mode, ProofMode)
+	assert.Equal(t, len(loaded.History), 2)
+	assert.Assert(t, loaded.Theorem.SameAs(pg.Theorem))
+	assert.Assert(t, loaded.Root.SameAs(pg.Root))
+
+	// a proof that doesn't follow from its theorem isn't loaded, since it
+	// could be used as a lemma
+	forged := filepath.Join(t.TempDir(), "forged.vll")
+	assert.NilError(t, ioutil.WriteFile(forged, []byte(`{
+		"mode": "Proof",
+		"statement": {"kind": "Root", "children": [{"kind": "White", "variable": "A"}]},
+		"theorem": {"kind": "Root"},
+		"history": [{"kind": "Root"}]
+	}`), 0644))
+	_, err = LoadPage(nil, forged)
+	assert.ErrorContains(t, err, "doesn't end with the statement")
+
+	// and neither is a statement that isn't a valid tree
+	assert.NilError(t, ioutil.WriteFile(forged, []byte(`{
+		"mode": "Create",
+		"statement": {"kind": "Root", "children": [
+			{"id": 7, "kind": "White", "children": [{"id": 7, "kind": "Black"}]},
+			{"kind": "Black", "variable": "A", "children": [{"kind": "White"}]}
+		]}
+	}`), 0644))
+	_, err = LoadPage(nil, forged)
+	assert.ErrorContains(t, err, "invalid tree")
+
+	// a file that doesn't exist yet starts out empty
+	empty, err := LoadPage(nil, filepath.Join(t.TempDir(), "new.vll"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(empty.Root.Children), 0)
+	assert.Equal(t, empty.Title(), "new")
+}
+
+func TestOpen(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.vll"), filepath.Join(dir, "b.vll")
+	ws, err := NewWorkspace(nil, a, b)
+	assert.NilError(t, err)
+	assert.Equal(t, len(ws.Pages), 2)
+	assert.Equal(t, ws.Page().Title(), "a")
+
+	// opening a file twice just shows it
+	pg, err := ws.Open(b)
+	assert.NilError(t, err)
+	assert.Equal(t, len(ws.Pages), 2)
+	assert.Equal(t, ws.Page(), pg)
+}
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, fileName("Lesson 1: Moving", "page"), "lesson-1-moving")
+	assert.Equal(t, fileName("  Modus ponens!", "page"), "modus-ponens")
+	assert.Equal(t, fileName("A/B\\C", "page"), "a-b-c")
+	assert.Equal(t, fileName("⊗⅋", "page"), "page")
+}
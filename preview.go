@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math"
+	"time"
+	"vll/page"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+const (
+	// how far outside a bubble's outermost center its outline is drawn, in world units
+	outlineMargin = 35
+	// how long one pulse of a pair about to annihilate takes
+	pulseTime = 600 * time.Millisecond
+)
+
+// drawDropPreview outlines the bubble the grabbed bubble would be dropped
+// into if it were let go at (x, y), in the theme's colors for whether that's
+// allowed or not. If the two would annihilate, both of them pulse instead.
+func drawDropPreview(win *pixelgl.Window, pg *page.Page, x, y int) {
+	if pg.Grabbed == nil || pg.GrabbedParent == nil {
+		return
+	}
+	target := pg.NearestAlternative(x, y)
+	if target == pg.GrabbedParent {
+		// it would just stay where it is
+		return
+	}
+	d, err := pg.DropDestination(target)
+	allowed, rejected := pixel.ToRGBA(pg.Theme.Allowed), pixel.ToRGBA(pg.Theme.Rejected)
+
+	outline := imdraw.New(nil)
+	switch {
+	case err != nil:
+		outline.Color = rejected
+		if target == pg.Root {
+			// there's nothing to drop it into, so it's the bubble itself that can't go there
+			target = pg.Grabbed
+		}
+		outlineBubble(outline, pg, target, 0)
+	case d.Effect == page.Annihilate:
+		phase := float64(time.Now().UnixNano()%int64(pulseTime)) / float64(pulseTime)
+		pulse := 0.5 + 0.5*math.Sin(2*math.Pi*phase)
+		outline.Color = allowed.Scaled(0.6 + 0.4*pulse)
+		outlineBubble(outline, pg, target, pulse)
+		outlineBubble(outline, pg, pg.Grabbed, pulse)
+	default:
+		outline.Color = allowed
+		outlineBubble(outline, pg, target, 0)
+	}
+	outline.Draw(win)
+}
+
+// outlineBubble circles a bubble and everything inside it, growing the circle
+// by a fraction of the margin to make it pulse
+func outlineBubble(outline *imdraw.IMDraw, pg *page.Page, b *page.Bubble, grow float64) {
+	radius := 0.0
+	b.Iterate(func(inside *page.Bubble) {
+		radius = math.Max(radius, page.Distance(b, inside))
+	})
+	radius += outlineMargin * (1 + 0.3*grow)
+
+	sx, sy := pg.Camera.ToScreen(b.X, b.Y)
+	// screen coordinates start at the top left, but window coordinates start at the bottom left
+	outline.Push(pixel.V(sx, float64(pg.Camera.Height)-sy))
+	outline.Circle(radius*pg.Camera.Zoom, 3)
+}
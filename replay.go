@@ -0,0 +1,43 @@
+package main
+
+import (
+	"vll/page"
+)
+
+// how much faster or slower one press makes a replay
+const speedStep = 1.5
+
+// controlReplay plays, pauses, steps through and changes the speed of a replay
+func controlReplay(ctl *controls, r *page.Replay) {
+	switch {
+	case ctl.JustPressed("play"):
+		r.Toggle()
+	case ctl.JustPressed("step-forward"):
+		r.Forward()
+	case ctl.JustPressed("step-back"):
+		r.Back()
+	case ctl.JustPressed("faster"):
+		r.SetSpeed(r.Speed * speedStep)
+	case ctl.JustPressed("slower"):
+		r.SetSpeed(r.Speed / speedStep)
+	}
+}
+
+// describeReplay fills in the section about a replay, with links that control it
+func (sb *sidebar) describeReplay(r *page.Replay, stop func()) {
+	s := sb.add("Replay")
+	s.color = modeColors[page.ProofMode]
+	s.line("Step %v of %v", r.Step, r.Steps())
+	if r.Playing {
+		s.line("Playing, %.2g steps a second", r.Speed)
+		s.link("Pause", r.Toggle)
+	} else {
+		s.line("Paused")
+		s.link("Play", r.Toggle)
+	}
+	s.link("Step back", r.Back)
+	s.link("Step forward", r.Forward)
+	s.link("Slower", func() { r.SetSpeed(r.Speed / speedStep) })
+	s.link("Faster", func() { r.SetSpeed(r.Speed * speedStep) })
+	s.link("Stop", stop)
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"vll/page"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+)
+
+// selectionShape is a box or lasso being dragged out to select bubbles with,
+// in window coordinates
+type selectionShape struct {
+	lasso  bool
+	points []pixel.Vec
+}
+
+func newSelectionShape(lasso bool, at pixel.Vec) *selectionShape {
+	return &selectionShape{lasso: lasso, points: []pixel.Vec{at}}
+}
+
+func (s *selectionShape) add(at pixel.Vec) {
+	if s.points[len(s.points)-1] != at {
+		s.points = append(s.points, at)
+	}
+}
+
+func (s *selectionShape) draw(win *pixelgl.Window, theme *page.Theme) {
+	outline := imdraw.New(nil)
+	outline.Color = theme.Selection
+	if s.lasso {
+		outline.Push(s.points...)
+		outline.Polygon(2)
+	} else {
+		outline.Push(s.points[0], s.points[len(s.points)-1])
+		outline.Rectangle(2)
+	}
+	outline.Draw(win)
+}
+
+// apply adds the bubbles inside the shape to the selection
+func (s *selectionShape) apply(pg *page.Page) {
+	xs := make([]int, len(s.points))
+	ys := make([]int, len(s.points))
+	for i, p := range s.points {
+		// window coordinates start at the bottom left, but screen coordinates start at the top left
+		xs[i], ys[i] = pg.Camera.ToWorld(int(p.X), pg.Camera.Height-int(p.Y))
+	}
+	if s.lasso {
+		pg.SelectLasso(xs, ys)
+	} else {
+		pg.SelectRect(xs[0], ys[0], xs[len(xs)-1], ys[len(ys)-1])
+	}
+}
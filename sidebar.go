@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+	"vll/page"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+)
+
+const (
+	// the width of the sidebar, which the canvas leaves alone
+	sidebarWidth = 225
+	sidebarScale = 1.5
+	// how many characters fit on a line of the sidebar
+	sidebarChars = 20
+	// how far one notch of the mouse wheel scrolls the sidebar
+	scrollStep = 30
+)
+
+var headingColor = pixel.RGB(0.3, 0.3, 0.4)
+
+// entry is a line of the sidebar, which does something when it's clicked if
+// it has a click function
+type entry struct {
+	text  string
+	click func()
+}
+
+// section is a part of the sidebar, which can be collapsed by clicking its heading
+type section struct {
+	title   string
+	color   color.Color
+	entries []entry
+}
+
+// sidebar is the panel down the left of the window. It's filled in again
+// every frame, but remembers which sections are collapsed and how far it's
+// scrolled.
+type sidebar struct {
+	sections  []*section
+	collapsed map[string]bool
+	scroll    float64
+	// what's been drawn that can be clicked, found while drawing
+	targets []target
+	// how tall everything was when it was last drawn
+	height float64
+
+	// the step that was clicked on, which is gone back to if it's clicked again
+	rewindTo    int
+	rewindUntil time.Time
+}
+
+type target struct {
+	bounds pixel.Rect
+	click  func()
+}
+
+func newSidebar() *sidebar {
+	return &sidebar{collapsed: make(map[string]bool), rewindTo: -1}
+}
+
+// reset clears the sections, ready for them to be filled in again
+func (sb *sidebar) reset() {
+	sb.sections = nil
+}
+
+// add starts a new section, and returns it so that entries can be added to it
+func (sb *sidebar) add(title string) *section {
+	s := &section{title: title, color: headingColor}
+	sb.sections = append(sb.sections, s)
+	return s
+}
+
+// line adds text to the section, wrapped to fit the sidebar
+func (s *section) line(format string, args ...interface{}) {
+	s.entries = append(s.entries, entry{text: wrap(fmt.Sprintf(format, args...), sidebarChars)})
+}
+
+// link adds text which does something when it's clicked
+func (s *section) link(str string, click func()) {
+	s.entries = append(s.entries, entry{text: wrap(str, sidebarChars), click: click})
+}
+
+// scrollBy scrolls the sidebar, keeping its contents in view
+func (sb *sidebar) scrollBy(notches float64, visible float64) {
+	sb.scroll -= notches * scrollStep
+	if sb.scroll > sb.height-visible {
+		sb.scroll = sb.height - visible
+	}
+	if sb.scroll < 0 {
+		sb.scroll = 0
+	}
+}
+
+// click does whatever was drawn at a position in window coordinates, and
+// returns whether there was anything there
+func (sb *sidebar) click(pos pixel.Vec) bool {
+	for _, t := range sb.targets {
+		if t.bounds.Contains(pos) {
+			t.click()
+			return true
+		}
+	}
+	return false
+}
+
+// draw lays the sections out downwards from top, in window coordinates, in
+// the colors of the theme
+func (sb *sidebar) draw(win *pixelgl.Window, atlas *text.Atlas, theme *page.Theme, top float64) {
+	sb.targets = nil
+	lineHeight := atlas.LineHeight() * sidebarScale
+	y := top + sb.scroll
+	backdrop := imdraw.New(nil)
+	txt := text.New(pixel.ZV, atlas)
+
+	for _, s := range sb.sections {
+		s := s
+		// the heading is a bar across the sidebar
+		heading := pixel.R(0, y-lineHeight, sidebarWidth, y)
+		backdrop.Color = s.color
+		backdrop.Push(heading.Min, heading.Max.Sub(pixel.V(0, 1)))
+		backdrop.Rectangle(0)
+		sb.targets = append(sb.targets, target{heading, func() { sb.collapsed[s.title] = !sb.collapsed[s.title] }})
+		marker := "-"
+		if sb.collapsed[s.title] {
+			marker = "+"
+		}
+		sb.print(txt, y, color.White, marker+" "+s.title)
+		y -= lineHeight
+
+		if sb.collapsed[s.title] {
+			continue
+		}
+		for _, e := range s.entries {
+			lines := 1
+			for _, r := range e.text {
+				if r == '\n' {
+					lines++
+				}
+			}
+			clr := theme.Text
+			if e.click != nil {
+				clr = theme.Link
+				sb.targets = append(sb.targets, target{pixel.R(0, y-float64(lines)*lineHeight, sidebarWidth, y), e.click})
+			}
+			sb.print(txt, y, clr, e.text)
+			y -= float64(lines) * lineHeight
+		}
+		y -= lineHeight / 2
+	}
+	sb.height = top + sb.scroll - y
+
+	backdrop.Draw(win)
+	txt.Draw(win, pixel.IM.Scaled(pixel.ZV, sidebarScale))
+}
+
+// print writes text starting at y, in window coordinates. The text is
+// scaled up when it's drawn, so it's written at a scaled down position.
+func (sb *sidebar) print(txt *text.Text, y float64, clr color.Color, str string) {
+	txt.Dot = pixel.V(4, y-txt.LineHeight*sidebarScale+txt.Atlas().Descent()*sidebarScale).Scaled(1 / sidebarScale)
+	txt.Orig = txt.Dot
+	txt.Color = clr
+	fmt.Fprint(txt, str)
+}
+
+// the color of the heading of the mode section, in each mode
+var modeColors = map[page.Mode]color.Color{
+	page.CreateMode:      pixel.RGB(0.35, 0.35, 0.6),
+	page.ProofMode:       pixel.RGB(0.2, 0.5, 0.3),
+	page.AssumptionMode:  pixel.RGB(0.6, 0.5, 0.1),
+	page.ContingencyMode: pixel.RGB(0.6, 0.2, 0.2),
+}
+
+// describe fills in the sections about the page: what mode it's in, the
+// statement and the proof of it so far, and the bubbles being pointed at and
+// selected
+func (sb *sidebar) describe(pg *page.Page, hovered *page.Bubble, status *notice) {
+	mode := sb.add(pg.Mode.String() + " mode")
+	mode.color = modeColors[pg.Mode]
+	mode.line("%v", pg.Title())
+	if pg.Stash != nil {
+		mode.line("Stashed proof of %v", formula(pg.Stash.Theorem))
+	}
+
+	if pg.Theorem == nil {
+		sb.add("Statement").line("%v", formula(pg.Root))
+	} else {
+		sb.add("Goal").line("%v", formula(pg.Root))
+		sb.add("Theorem").line("%v", formula(pg.Theorem))
+
+		steps := sb.add("Steps")
+		if time.Now().After(sb.rewindUntil) {
+			sb.rewindTo = -1
+		}
+		for i, step := range pg.History {
+			i := i
+			str := fmt.Sprintf("%v. %v", i, formula(step))
+			if i == len(pg.History)-1 {
+				steps.line("%v", str)
+				continue
+			}
+			steps.link(str, func() {
+				if sb.rewindTo != i {
+					sb.rewindTo = i
+					sb.rewindUntil = time.Now().Add(confirmTime)
+					return
+				}
+				sb.rewindTo = -1
+				status.Set(pg.Rewind(i))
+			})
+			if i == sb.rewindTo {
+				steps.line("Click again to go back to this step, forgetting the ones after it.")
+			}
+		}
+	}
+
+	if hovered != nil && hovered != pg.Root {
+		pointed := sb.add("Pointing at")
+		pointed.line("%v bubble %v", hovered.Kind, hovered.ID)
+		if hovered.Variable != "" {
+			pointed.line("Variable %v", hovered.Variable)
+		} else {
+			pointed.line("%v", formula(hovered))
+		}
+		pointed.line("%v deep, %v high", hovered.Depth, hovered.Height)
+	}
+
+	if len(pg.Highlighted) > 0 {
+		selected := sb.add("Selection")
+		selected.line("%v selected", len(pg.Highlighted))
+		for _, b := range pg.Highlighted {
+			selected.line("%v %v", b.Kind, formula(b))
+		}
+	}
+}
+
+// formula is a bubble written with the connectives of linear logic, with the
+// empty statement spelled out
+func formula(b *page.Bubble) string {
+	if str := strings.TrimSpace(b.Pretty()); str != "" {
+		return str
+	}
+	return "(nothing)"
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"vll/eventlog"
+	"vll/page"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+)
+
+const (
+	// how long a notice stays in the sidebar
+	noticeTime = 4 * time.Second
+	// how long the bubble a notice is about stays marked
+	annotationTime = 2 * time.Second
+)
+
+// notice is a message shown in the sidebar for a little while, like the
+// reason an action couldn't be done
+type notice struct {
+	text  string
+	until time.Time
+	// the bubble a rule was broken at, if there is one
+	at      *page.Bubble
+	atUntil time.Time
+}
+
+// Set shows the error, if there is one
+func (n *notice) Set(err error) {
+	if err == nil {
+		return
+	}
+	n.text = err.Error()
+	n.until = time.Now().Add(noticeTime)
+	page.Log.Info("rejected", eventlog.F("reason", n.text))
+
+	var rejection *page.Rejection
+	n.at = nil
+	if errors.As(err, &rejection) && rejection.At != nil {
+		n.at = rejection.At
+		n.atUntil = time.Now().Add(annotationTime)
+	}
+}
+
+// annotate circles the bubble the notice is about, with the reason next to it
+func (n *notice) annotate(win *pixelgl.Window, pg *page.Page) {
+	if n.at == nil || time.Now().After(n.atUntil) {
+		return
+	}
+	sx, sy := pg.Camera.ToScreen(n.at.X, n.at.Y)
+	// screen coordinates start at the top left, but window coordinates start at the bottom left
+	at := pixel.V(sx, float64(pg.Camera.Height)-sy)
+	radius := 30 * pg.Camera.Zoom
+
+	mark := imdraw.New(nil)
+	mark.Color = pg.Theme.Marked
+	mark.Push(at)
+	mark.Circle(radius, 3)
+	mark.Draw(win)
+
+	txt := text.New(at.Add(pixel.V(radius+5, 0)), pg.Atlas)
+	txt.Color = mark.Color
+	fmt.Fprint(txt, n.text)
+	txt.Draw(win, pixel.IM.Scaled(txt.Orig, 1.5))
+}
+
+func (n *notice) String() string {
+	if time.Now().After(n.until) {
+		return ""
+	}
+	return n.text
+}
+
+// wrap breaks text into lines of at most width characters, so it fits in the sidebar
+func wrap(text string, width int) string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		if line != "" && len(line)+1+len(word) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" {
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
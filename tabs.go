@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"image/color"
+	"vll/page"
+
+	"github.com/faiface/pixel"
+	"github.com/faiface/pixel/imdraw"
+	"github.com/faiface/pixel/pixelgl"
+	"github.com/faiface/pixel/text"
+)
+
+const (
+	tabScale = 2
+	// how many characters of a page's title fit on its tab
+	tabChars = 14
+)
+
+// tabs lists the pages of the workspace at the top of the sidebar. Clicking
+// a tab shows its page, and dragging the tab of a proved page onto the canvas
+// uses what it proves as a lemma.
+type tabs struct {
+	ws *page.Workspace
+	// the tab being dragged, or -1
+	dragging int
+}
+
+func newTabs(ws *page.Workspace) *tabs {
+	return &tabs{ws: ws, dragging: -1}
+}
+
+// bounds is where the tab of the i'th page is, in window coordinates
+func (t *tabs) bounds(win *pixelgl.Window, atlas *text.Atlas, i int) pixel.Rect {
+	lineHeight := atlas.LineHeight() * tabScale
+	top := win.Bounds().H() - float64(i)*lineHeight
+	return pixel.R(0, top-lineHeight, sidebarWidth, top)
+}
+
+// height is how much of the sidebar the tabs take up
+func (t *tabs) height(atlas *text.Atlas) float64 {
+	return float64(len(t.ws.Pages)) * atlas.LineHeight() * tabScale
+}
+
+// at is the tab at a position in window coordinates, or -1 if there isn't one
+func (t *tabs) at(win *pixelgl.Window, atlas *text.Atlas, pos pixel.Vec) int {
+	for i := range t.ws.Pages {
+		if t.bounds(win, atlas, i).Contains(pos) {
+			return i
+		}
+	}
+	return -1
+}
+
+// label is what's written on a page's tab
+func label(pg *page.Page) string {
+	title := pg.Title()
+	if runes := []rune(title); len(runes) > tabChars {
+		title = string(runes[:tabChars-1]) + "~"
+	}
+	return title
+}
+
+func (t *tabs) draw(win *pixelgl.Window, atlas *text.Atlas) {
+	backdrop := imdraw.New(nil)
+	for i := range t.ws.Pages {
+		if i == t.ws.Current {
+			backdrop.Color = pixel.RGB(0.35, 0.35, 0.45)
+		} else {
+			backdrop.Color = pixel.RGB(0.15, 0.15, 0.2)
+		}
+		r := t.bounds(win, atlas, i)
+		backdrop.Push(r.Min, r.Max.Sub(pixel.V(0, 1)))
+		backdrop.Rectangle(0)
+	}
+	backdrop.Draw(win)
+
+	for i, pg := range t.ws.Pages {
+		r := t.bounds(win, atlas, i)
+		txt := text.New(pixel.V(r.Min.X+4, r.Min.Y+atlas.Descent()*tabScale), atlas)
+		txt.Color = color.White
+		if _, err := pg.Lemma(); err == nil {
+			// proved pages can be dragged onto others
+			txt.Color = pixel.RGB(0.5, 1, 0.5)
+		}
+		fmt.Fprint(txt, label(pg))
+		txt.Draw(win, pixel.IM.Scaled(txt.Orig, tabScale))
+	}
+
+	// the tab being dragged follows the mouse
+	if t.dragging >= 0 {
+		txt := text.New(win.MousePosition(), atlas)
+		txt.Color = pixel.RGB(0.5, 1, 0.5)
+		fmt.Fprint(txt, label(t.ws.Pages[t.dragging]))
+		txt.Draw(win, pixel.IM.Scaled(txt.Orig, tabScale))
+	}
+}
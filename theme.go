@@ -0,0 +1,20 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"vll/page"
+)
+
+var themeName = flag.String("theme", page.DefaultTheme.Name, "the colors to draw in: "+strings.Join(page.ThemeNames(), ", "))
+
+// chooseTheme draws the workspace in the theme picked on the command line
+func chooseTheme(ws *page.Workspace) error {
+	theme, ok := page.ThemeNamed(*themeName)
+	if !ok {
+		return fmt.Errorf("there's no theme called %q; try one of %v", *themeName, strings.Join(page.ThemeNames(), ", "))
+	}
+	ws.SetTheme(theme)
+	return nil
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+	"vll/keymap"
+	"vll/page"
+
+	"github.com/faiface/pixel"
+)
+
+var startTutorial = flag.Bool("tutorial", false, "start with the tutorial, picking up where it was left off")
+
+var tutorialColor = pixel.RGB(0.15, 0.45, 0.55)
+
+// actionPattern matches an action's name in braces, in a lesson's instructions
+var actionPattern = regexp.MustCompile(`\{([a-z-]+)\}`)
+
+// instructions spells out the bindings of the actions a lesson's instructions mention
+func instructions(km *keymap.Keymap, str string) string {
+	return actionPattern.ReplaceAllStringFunc(str, func(name string) string {
+		action := km.Action(name[1 : len(name)-1])
+		if action == nil || len(action.Bindings) == 0 {
+			return name
+		}
+		return action.Bindings[0].String()
+	})
+}
+
+// describeTutorial fills in the section about the lesson being done, with
+// links to go on to the next lesson once it's finished, or to start it over
+func (sb *sidebar) describeTutorial(tut *page.Tutorial, km *keymap.Keymap, next, restart func()) {
+	lesson := tut.Lesson()
+	s := sb.add(fmt.Sprintf("Lesson %v of %v", tut.Current+1, len(tut.Lessons)))
+	s.color = tutorialColor
+	s.line("%v", lesson.Name)
+	for _, str := range lesson.Instructions {
+		s.line("%v", instructions(km, str))
+	}
+	if tut.Done() {
+		s.line("Well done!")
+		if tut.Current+1 < len(tut.Lessons) {
+			s.link("Next lesson", next)
+		} else {
+			s.line("That was the last lesson.")
+		}
+	}
+	s.link("Start the lesson over", restart)
+	s.line("%v of %v lessons finished", tut.Progress(), len(tut.Lessons))
+}
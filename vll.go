@@ -1,21 +1,26 @@
 package main
 
 import (
+	"flag"
 	"fmt"
-	"image/color"
 	"math"
+	"os"
 	"strings"
 	"time"
 	"vll/page"
 
 	"github.com/faiface/pixel"
 	"github.com/faiface/pixel/pixelgl"
-	"github.com/faiface/pixel/text"
 )
 
 const (
+	// initial size of the window
 	width  = 1024
 	height = 640
+	// how much one notch of the mouse wheel zooms in or out
+	zoomStep = 1.1
+	// how long a confirmation prompt waits for the action to be repeated
+	confirmTime = 3 * time.Second
 )
 
 // how should exponentials work?
@@ -29,7 +34,6 @@ const (
 // if it's around a unit, you enter contingency mode, which lets you freely edit the interior until you exit it
 // ! requires copying
 // i think it makes sense to implement copying with double-clicks, a double click in a blue loop copies the contents, and it is grabbed on the second click
-// maybe it also makes sense to implement ctrl-c ctrl-v copy-paste controls too -- this would also allow for copying multiple bubbles at once
 
 // okay, how about additives?
 // and additive bubble consists of an outershell, along with a
@@ -40,8 +44,6 @@ const (
 // Implement deleting and adding loops
 // Implement proof vs creative mode
 // Implement multi-highlighting
-// Implement hover highlighting (so you can see where you're dropping something)
-//  do this with a map so it's fast
 // To look okay, we need to prevent bubbles from ever getting split. Not sure how to enforce this.
 
 // really need to clean this up a bit
@@ -82,103 +84,379 @@ func run() {
 	win, err := pixelgl.NewWindow(pixelgl.WindowConfig{
 		Bounds:    pixel.R(0, 0, float64(width), float64(height)),
 		VSync:     true,
-		Resizable: false,
+		Resizable: true,
 	})
 	if err != nil {
 		panic(err)
 	}
 
-	pg := page.NewPage(win)
+	ws, err := page.NewWorkspace(win, flag.Args()...)
+	if err == nil {
+		err = chooseTheme(ws)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	exercises, err := openExercises(ws)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if exercises != nil {
+		if flag.NArg() == 0 {
+			// there's no need for the empty page
+			ws.Close(0)
+		}
+		ws.Switch(len(ws.Pages) - len(exercises.Pages))
+	}
+	// saveExercises saves the proofs of the exercises, so that none of them are lost
+	saveExercises := func() {
+		if exercises == nil {
+			return
+		}
+		if err := exercises.Save(); err != nil {
+			fmt.Fprintln(os.Stderr, "couldn't save the exercises:", err)
+		}
+	}
+	defer saveExercises()
+	pg := ws.Page()
+	km := newKeymap()
+	ctl := &controls{km: km, win: win, pg: pg}
+	tabs := newTabs(ws)
+	sb := newSidebar()
 
 	go func() {
 		for {
-			update(pg)
+			update(ws.Page())
 			time.Sleep(60 * time.Millisecond)
 		}
 	}()
 
 	grabbedX := 0
 	grabbedY := 0
-	clickTime := time.Now()
+	// how far the grabbed bubble has been dragged that it hasn't been moved yet
+	var dragX, dragY float64
 	var clickOwner *page.Bubble
+	panning := false
+	showHelp := false
+	showLog := false
+	var confirmEditUntil time.Time
+	var confirmCloseUntil time.Time
+	var status notice
+	var shape *selectionShape
+	// the proof being played back, if there is one, and when the last frame was drawn
+	var replay *page.Replay
+	lastFrame := time.Now()
+
+	// showPage switches to another page, dropping whatever was going on in this one
+	showPage := func(i int) {
+		if err := ws.Switch(i); err != nil {
+			status.Set(err)
+			return
+		}
+		pg.Grabbed = nil
+		replay = nil
+		pg = ws.Page()
+		ctl.pg = pg
+		clickOwner = nil
+		panning = false
+		shape = nil
+		confirmEditUntil = time.Time{}
+		confirmCloseUntil = time.Time{}
+	}
+
+	// openLesson shows the tutorial's current lesson, starting it over
+	var tut *page.Tutorial
+	openLesson := func() {
+		if tut == nil {
+			path, err := page.DefaultTutorialPath()
+			if err == nil {
+				tut, err = page.LoadTutorial(path)
+			}
+			if err != nil {
+				status.Set(err)
+				return
+			}
+		}
+		if err := tut.Open(ws); err != nil {
+			status.Set(err)
+			return
+		}
+		showPage(ws.Current)
+	}
+	nextLesson := func() {
+		if err := tut.Next(ws); err != nil {
+			status.Set(err)
+			return
+		}
+		showPage(ws.Current)
+	}
+	if *startTutorial {
+		openLesson()
+	}
 
 	for !win.Closed() {
 		win.Update()
+		km.Update(win)
 
-		p := pg.DrawPicture()
-		s := pixel.NewSprite(p, p.Bounds())
-		s.Draw(win, pixel.IM.Moved(win.Bounds().Center()))
+		// keep the camera the same size as the window
+		bounds := win.Bounds()
+		if int(bounds.W()) != pg.Camera.Width || int(bounds.H()) != pg.Camera.Height {
+			pg.Camera.Resize(int(bounds.W()), int(bounds.H()))
+		}
 
-		// TODO: figure out why these offsets are needed for things to line up properly
-		x := int(win.MousePosition().X) - 1
-		y := int(height-win.MousePosition().Y) + 38
+		// a replay is drawn instead of the page, until it's stopped
+		shown := pg
+		if replay != nil {
+			replay.Advance(time.Since(lastFrame))
+			shown = replay.Page
+		}
+		lastFrame = time.Now()
+
+		win.Clear(shown.Theme.Backdrop)
+		p := shown.DrawPicture()
+		s := pixel.NewSprite(p, p.Bounds())
+		s.Draw(win, pixel.IM.Moved(bounds.Center()))
 
-		pg.Label()
+		// the mouse position has its origin at the bottom left, but the screen
+		// coordinates used by the camera start at the top left
+		screenX := int(win.MousePosition().X)
+		screenY := int(bounds.H() - win.MousePosition().Y)
+		x, y := pg.Camera.ToWorld(screenX, screenY)
 
-		basicTxt := text.New(pixel.V(0, height-20), pg.Atlas)
+		shown.Label()
 
-		if win.JustPressed(pixelgl.KeyEscape) {
+		if ctl.JustPressed("quit") {
 			return
 		}
-		if win.JustPressed(pixelgl.KeyH) {
-			// toggle help screen with all the commands
+		if ctl.JustPressed("replay") {
+			if replay != nil {
+				replay = nil
+			} else if replay, err = page.NewReplay(pg); err != nil {
+				status.Set(err)
+			}
+		}
+		ctl.replaying = replay != nil
+		if replay != nil {
+			controlReplay(ctl, replay)
+		}
+
+		// Pages are switched between with their tabs, and a proved page's tab
+		// can be dragged onto the canvas to use what it proves as a lemma
+		if tab := tabs.at(win, pg.Atlas, win.MousePosition()); ctl.JustPressed("grab") && tab >= 0 {
+			tabs.dragging = tab
+			continue
+		}
+		if ctl.JustPressed("grab") && win.MousePosition().X < sidebarWidth && sb.click(win.MousePosition()) {
+			continue
+		}
+		if tabs.dragging >= 0 && ctl.JustReleased("grab") {
+			from := ws.Pages[tabs.dragging]
+			tabs.dragging = -1
+			if tab := tabs.at(win, pg.Atlas, win.MousePosition()); tab >= 0 {
+				showPage(tab)
+			} else if err := pg.CheckUseLemma(from, pg.BelongsTo(x, y)); err != nil {
+				status.Set(err)
+			} else {
+				owner := pg.BelongsTo(x, y)
+				pg.Execute(func() { pg.UseLemma(from, owner, x, y) })
+			}
+			continue
+		}
+		if ctl.JustPressed("new-page") {
+			ws.New()
+			showPage(len(ws.Pages) - 1)
+		}
+		if ctl.JustPressed("next-page") {
+			showPage((ws.Current + 1) % len(ws.Pages))
+		}
+		if ctl.JustPressed("previous-page") {
+			showPage((ws.Current + len(ws.Pages) - 1) % len(ws.Pages))
+		}
+		if ctl.JustPressed("save") {
+			// an exercise's page is saved along with the others, as the student's proofs
+			save := pg.Save
+			if exercises != nil {
+				if _, ok := exercises.Exercise(pg); ok {
+					save = exercises.Save
+				}
+			}
+			status.Set(save())
+		}
+		// Closing a page throws it away, so ask first
+		if ctl.JustPressed("close-page") {
+			if time.Now().Before(confirmCloseUntil) {
+				if err := ws.Close(ws.Current); err != nil {
+					status.Set(err)
+				}
+				showPage(ws.Current)
+			} else {
+				confirmCloseUntil = time.Now().Add(confirmTime)
+			}
+		}
+		if ctl.JustPressed("tutorial") {
+			openLesson()
+		}
+		if ctl.JustPressed("theme") {
+			ws.SetTheme(pg.Theme.Next())
+		}
+		if ctl.JustPressed("help") && (win.Typed() == "" || !typingVariable(pg)) {
+			showHelp = !showHelp
+		}
+		if ctl.JustPressed("log") {
+			showLog = !showLog
+		}
+
+		// Going back to create mode puts the proof aside, so ask first
+		if ctl.JustPressed("edit") {
+			if time.Now().Before(confirmEditUntil) {
+				pg.EditStatement()
+				confirmEditUntil = time.Time{}
+			} else {
+				confirmEditUntil = time.Now().Add(confirmTime)
+			}
 		}
 
 		// Yank bubbles out of their parents (if change in velocity is sufficiently high)
 		if pg.Grabbed != nil && pg.Grabbed.Parent != nil && pg.Grabbed.Parent != pg.Root {
-			if !pg.AssumptionMode || pg.Grabbed.AssumptionPair != nil && pg.Grabbed.Parent.Kind != page.RED && pg.Grabbed.Parent.Kind != page.BLUE {
+			if pg.Mode != page.AssumptionMode || pg.Grabbed.AssumptionPair != nil && !pg.Grabbed.Parent.Kind.IsExponential() {
 				if pg.Grabbed.VX*pg.Grabbed.VX+pg.Grabbed.VY*pg.Grabbed.VY > 400 {
 					pg.Execute(func() { pg.Delete(pg.Grabbed) })
 				}
 			}
 		}
 
-		// The drag part of drag-and-drop behavior for a grabbed bubble
+		// The drag part of drag-and-drop behavior for a grabbed bubble. Whatever
+		// is left over of a world unit is carried on to the next frame, so that
+		// slow drags still move the bubble while zoomed in.
 		if pg.Grabbed != nil && grabbedX != 0 && grabbedY != 0 {
-			dx := int(win.MousePreviousPosition().X - win.MousePosition().X)
-			dy := int(win.MousePosition().Y - win.MousePreviousPosition().Y)
+			dragX += (win.MousePreviousPosition().X - win.MousePosition().X) / pg.Camera.Zoom
+			dragY += (win.MousePosition().Y - win.MousePreviousPosition().Y) / pg.Camera.Zoom
+			dx, dy := int(dragX), int(dragY)
+			dragX -= float64(dx)
+			dragY -= float64(dy)
 			pg.Grabbed.MoveBy(dx, dy)
+		} else {
+			dragX, dragY = 0, 0
+		}
+
+		// Scrolling zooms in and out around the mouse, or scrolls the sidebar
+		if scroll := win.MouseScroll().Y; scroll != 0 && win.MousePosition().X < sidebarWidth {
+			sb.scrollBy(scroll, bounds.H()-tabs.height(pg.Atlas))
+		} else if scroll != 0 {
+			pg.Camera.ZoomAt(screenX, screenY, math.Pow(zoomStep, scroll))
 		}
 
-		// Print sidebar info
-		basicTxt.Color = color.White
-		b := pg.BelongsTo(x, y)
-		fmt.Fprintln(basicTxt, pg.Mode+" mode:")
-		fmt.Fprintln(basicTxt, x, y, page.Name(b.Kind), b.Variable)
-		fmt.Fprintln(basicTxt)
-		fmt.Fprintln(basicTxt, pg.Root.Sprint())
-		fmt.Fprintln(basicTxt, "Assumption Mode:\n", pg.AssumptionMode, pg.AssumptionPair)
-		basicTxt.Draw(win, pixel.IM.Scaled(basicTxt.Orig, 2))
+		// Dragging the background (or anywhere with the middle button) pans the camera
+		if ctl.JustPressed("pan") {
+			panning = true
+		}
+		if ctl.JustReleased("pan") || ctl.JustReleased("grab") {
+			panning = false
+		}
+		if panning {
+			dx := win.MousePosition().X - win.MousePreviousPosition().X
+			dy := win.MousePreviousPosition().Y - win.MousePosition().Y
+			pg.Camera.Pan(dx, dy)
+		}
 
-		win.SetTitle(pg.Root.Tolestra() + " | Mode: " + pg.Mode)
+		// Fill in the sidebar
+		sb.reset()
+		var hovered *page.Bubble
+		if win.MousePosition().X >= sidebarWidth && replay == nil {
+			hovered = pg.BelongsTo(x, y)
+		}
+		sb.describe(pg, hovered, &status)
+		if tut != nil && tut.Page == pg {
+			if _, err := tut.Check(); err != nil {
+				status.Set(err)
+			}
+			sb.describeTutorial(tut, km, nextLesson, openLesson)
+		}
+		if exercises != nil {
+			sb.describeExercise(exercises, pg)
+		}
+		if replay != nil {
+			sb.describeReplay(replay, func() { replay = nil })
+		}
+		feedback := sb.add("Feedback")
+		if msg := status.String(); msg != "" {
+			feedback.line("%v", msg)
+		}
+		if time.Now().Before(confirmEditUntil) {
+			feedback.line("Press again to edit the statement. The proof so far will be stashed, and picked back up if you prove the same statement again.")
+		}
+		if time.Now().Before(confirmCloseUntil) {
+			feedback.line("Press again to close this page. Anything that isn't saved will be lost.")
+		}
+		sb.draw(win, pg.Atlas, pg.Theme, bounds.H()-tabs.height(pg.Atlas))
+		tabs.draw(win, pg.Atlas)
+
+		status.annotate(win, pg)
+		if showLog {
+			drawLog(win, pg.Atlas)
+		}
+
+		if showHelp {
+			mode := pg.Mode.String()
+			if replay != nil {
+				mode = replaying
+			}
+			drawHelp(win, pg.Atlas, km, mode)
+		}
+
+		win.SetTitle(pg.Title() + ": " + pg.Root.Tolestra() + " | Mode: " + pg.Mode.String())
+
+		// nothing can be changed while a proof is being played back
+		if replay != nil {
+			continue
+		}
+
+		// Selecting works the same way in every mode
+		if ctl.JustPressed("siblings") {
+			pg.SelectSiblings()
+		}
+		if ctl.JustPressed("subtree") {
+			pg.SelectSubtree()
+		}
+		handleClipboard(ctl, &status, x, y)
+		if ctl.JustPressed("lasso") {
+			shape = newSelectionShape(true, win.MousePosition())
+			continue
+		}
+		if shape != nil {
+			shape.add(win.MousePosition())
+			if ctl.JustReleased("select") || ctl.JustReleased("lasso") {
+				shape.apply(pg)
+				shape = nil
+				continue
+			}
+			shape.draw(win, pg.Theme)
+		}
 
 		switch pg.Mode {
-		case "Create":
+		case page.CreateMode, page.ContingencyMode:
 			// New bubbles with variable names are created when text is typed
-			if str := win.Typed(); strings.TrimSpace(str) != "" || win.JustPressed(pixelgl.KeySpace) {
-				switch str {
-				case "!":
-					if len(pg.Highlighted) > 0 && pg.Grabbed == nil {
-						subject := pg.Highlighted[0]
-						if pg.AssumptionPair == nil || (subject != pg.AssumptionPair.Positive && subject != pg.AssumptionPair.Negative) {
-							loopKind := page.BLUE
-							pg.Execute(func() { pg.Loop(loopKind, pg.Highlighted...) })
-						}
+			if str := win.Typed(); strings.TrimSpace(str) != "" || ctl.JustPressed("unit") ||
+				ctl.JustPressed("of-course") || ctl.JustPressed("why-not") {
+				switch {
+				case ctl.JustPressed("of-course"):
+					if err := pg.CheckOfCourse(pg.Highlighted...); err != nil {
+						status.Set(err)
+					} else if pg.Grabbed == nil {
+						pg.Execute(func() { pg.Loop(page.BLUE, pg.Highlighted...) })
 					}
-				case "?":
-					if len(pg.Highlighted) > 0 && pg.Grabbed == nil {
-						if len(pg.Highlighted) > 0 && pg.Grabbed == nil {
-							subject := pg.Highlighted[0]
-							if pg.AssumptionPair == nil || (subject != pg.AssumptionPair.Positive && subject != pg.AssumptionPair.Negative) {
-								loopKind := page.RED
-								pg.Execute(func() { pg.Loop(loopKind, pg.Highlighted...) })
-							}
-						}
+				case ctl.JustPressed("why-not"):
+					if err := pg.CheckLoop(pg.Highlighted...); err != nil {
+						status.Set(err)
+					} else if pg.Grabbed == nil {
+						pg.Execute(func() { pg.Loop(page.RED, pg.Highlighted...) })
 					}
 				default:
 					if len(pg.Highlighted) == 1 && !pg.IsHighlighted(pg.Root) {
 						highlighted := pg.Highlighted[0]
-						if highlighted.Kind != page.BLUE && highlighted.Kind != page.RED {
+						if !highlighted.Kind.IsExponential() {
 							pg.Execute(func() {
 								if highlighted.Variable == "" {
 									highlighted.Insert(pg.NewBubble(grabbedX, grabbedY, str, highlighted.Kind))
@@ -192,237 +470,177 @@ func run() {
 				}
 			}
 			// Left click has drag and drop behavior
-			if win.JustPressed(pixelgl.MouseButtonLeft) {
+			if ctl.JustPressed("grab") || ctl.JustPressed("select") {
 				owner := pg.BelongsTo(x, y)
 				grabbedX = x
 				grabbedY = y
-				if owner == clickOwner && time.Now().Sub(clickTime) < time.Duration(350*time.Millisecond) {
-					fmt.Println("doubleclick")
-					newb := owner.Copy()
-					pg.Place(owner.Parent, newb)
-					pg.Grab(newb, x, y)
-				} else {
-					clickTime = time.Now()
-					clickOwner = owner
-					pg.Grab(owner, x, y)
-				}
-				if owner == pg.Root {
+				switch {
+				case owner == pg.Root && ctl.JustPressed("select"):
+					// dragging out a box adds everything inside it to the selection
+					shape = newSelectionShape(false, win.MousePosition())
+				case owner == pg.Root:
 					pg.Highlighted = nil
 					if len(pg.Root.Children) == 0 {
 						owner = pg.NewBubble(x, y, "", page.WHITE)
 						pg.Root.Insert(owner)
 						pg.Grab(owner, x, y)
-					}
-				} else {
-					if win.Pressed(pixelgl.KeyLeftShift) || win.Pressed(pixelgl.KeyLeftShift) {
-						fmt.Println("shifty")
-						pg.Highlighted = append(pg.Highlighted, owner)
 					} else {
-						pg.Grab(owner, x, y)
+						// dragging the background pans instead of moving everything
+						panning = true
 					}
+				case ctl.JustPressed("select"):
+					pg.Toggle(owner)
+				case owner == clickOwner && ctl.JustPressed("copy") && (pg.Mode != page.ContingencyMode || pg.InContingency(owner)):
+					page.Log.Info("copy", page.Field("bubble", owner))
+					newb := owner.Copy()
+					pg.Place(owner.Parent, newb)
+					pg.Grab(newb, x, y)
+				default:
+					clickOwner = owner
+					pg.Grab(owner, x, y)
 				}
-
 			}
 
-			if win.JustReleased(pixelgl.MouseButtonLeft) {
+			if ctl.JustReleased("grab") {
 				owner := pg.NearestAlternative(x, y)
-				if owner.Kind != page.RED && owner.Kind != page.BLUE && pg.Grabbed != nil {
+				if pg.Mode == page.ContingencyMode && !pg.InContingency(owner) && pg.Grabbed != nil {
+					// nothing can leave the contingency
+					status.Set(&page.Rejection{Reason: "nothing can leave the contingency", At: pg.Contingency})
+					pg.Execute(func() { pg.ReleaseInto(pg.GrabbedParent) })
+				} else if !owner.Kind.IsExponential() && pg.Grabbed != nil {
 					pg.Execute(func() { pg.ReleaseInto(owner) })
 				}
 				pg.Grabbed = nil
 			}
 
 			// Right click creates new multiplicative units
-			if win.JustPressed(pixelgl.MouseButtonRight) {
+			if ctl.JustPressed("bubble") {
 				// Insert a new bubble
 				owner := pg.BelongsTo(x, y)
-				if owner.Kind != page.RED && owner.Kind != page.BLUE {
-					pg.Grab(pg.NewBubble(x, y, "", owner.OppositePolarity()), x, y)
+				if !owner.Kind.IsExponential() && (pg.Mode != page.ContingencyMode || pg.InContingency(owner)) {
+					pg.Grab(pg.NewBubble(x, y, "", owner.Kind.Polarity().Dual()), x, y)
 					pg.Execute(func() { pg.ReleaseInto(owner) })
 				}
 			}
 
-			if win.JustReleased(pixelgl.MouseButtonRight) {
+			if ctl.JustReleased("bubble") {
 				pg.Grabbed = nil
 				pg.Highlighted = nil
 			}
 
-			if win.JustPressed(pixelgl.KeyTab) {
+			if ctl.JustPressed("loop") {
 				// insert a loop around highlighted bubbles
-				if len(pg.Highlighted) > 0 && pg.Grabbed == nil {
-					parent := pg.Highlighted[0].Parent
-					loopKind := parent.OppositePolarity()
+				if err := pg.CheckLoop(pg.Highlighted...); err != nil {
+					status.Set(err)
+				} else if pg.Grabbed == nil {
+					loopKind := pg.Highlighted[0].Parent.Kind.Polarity().Dual()
 					if len(pg.Highlighted) == 1 {
-						loopKind = pg.Highlighted[0].OppositePolarity()
-					} else if parent == pg.Root {
-						continue
+						loopKind = pg.Highlighted[0].Kind.Polarity().Dual()
 					}
 					pg.Execute(func() { pg.Loop(loopKind, pg.Highlighted...) })
 				}
 			}
 
-			if win.JustPressed(pixelgl.KeyBackspace) || win.JustPressed(pixelgl.KeyDelete) {
+			if ctl.JustPressed("delete") {
 				// delete a bubble in create mode
 				// delete a loop in proof mode
-				if pg.Grabbed == nil {
-					pg.Execute(func() {
-						fmt.Println("deleting")
-						for _, highlighted := range pg.Highlighted {
-							newParent := highlighted.Parent
-							pg.Delete(highlighted)
-							for _, child := range highlighted.Children {
-								pg.Place(newParent, child)
-							}
-						}
-					})
+				if err := pg.CheckDelete(); err != nil {
+					status.Set(err)
+				} else if pg.Grabbed == nil {
+					pg.Execute(pg.DeleteSelection)
 				}
 			}
 
-			if win.JustPressed(pixelgl.KeyEnter) {
-				pg.Mode = "Proof"
+			if ctl.JustPressed("prove") || ctl.JustPressed("done") {
+				pg.SetMode(page.ProofMode)
 			}
-		case "Proof":
-			if win.JustPressed(pixelgl.KeyBackspace) || win.JustPressed(pixelgl.KeyDelete) {
+		case page.ProofMode, page.AssumptionMode:
+			if ctl.JustPressed("delete") {
 				// delete a loop in proof mode
-				if pg.Grabbed == nil {
-					pg.Execute(func() {
-						var blue *page.Bubble
-						for _, highlighted := range pg.Highlighted {
-							if pg.AssumptionPair != nil && (highlighted == pg.AssumptionPair.Positive || highlighted == pg.AssumptionPair.Negative) {
-								return
-							}
-
-							// if a blue loop is highlighted, along with any of its descendents (but nothing else), delete the entire bubble
-							if highlighted.Kind == page.BLUE {
-								blue = highlighted
-							}
-
-							if len(highlighted.Children) == 1 && highlighted.Variable == "" && highlighted.Parent != nil && highlighted.IsMult() {
-								child := highlighted.Children[0]
-								newParent := highlighted.Parent
-								pg.Delete(highlighted)
-								pg.Place(newParent, child)
-							}
-
-							// allow deletion of empty bubbles with a parent of the same color
-							if len(highlighted.Children) == 0 && highlighted.Variable == "" {
-								if highlighted.Parent != nil && highlighted.Parent.Kind == highlighted.Kind && highlighted.IsMult() {
-									pg.Delete(highlighted)
-								}
-							}
-						}
-						if blue != nil {
-							fmt.Println("blue")
-
-							if len(pg.Highlighted) == 1 {
-								for _, child := range blue.Children {
-									newParent := blue.Parent
-									pg.Delete(blue)
-									pg.Place(newParent, child)
-								}
-							} else {
-								if page.LCA(pg.Highlighted...) == blue {
-									pg.Delete(blue)
-								}
-							}
-						}
-						// only delete a red loop if it's child is black and its grandkids are red loops
-						if len(pg.Highlighted) == 1 && pg.Highlighted[0].Kind == page.RED {
-							subject := pg.Highlighted[0]
-							if len(subject.Children) == 1 && subject.Children[0].Kind == page.BLACK {
-								child := subject.Children[0]
-								allred := true
-								for _, grandkid := range child.Children {
-									if grandkid.Kind != page.RED {
-										allred = false
-									}
-								}
-								if allred {
-									newParent := subject.Parent
-									pg.Delete(subject)
-									pg.Place(newParent, child)
-								}
-							}
-						}
-					})
+				if err := pg.CheckDelete(); err != nil {
+					status.Set(err)
+				} else if pg.Grabbed == nil {
+					pg.Execute(pg.DeleteLoops)
 				}
 			}
 			// Left click has drag and drop behavior
-			if win.JustPressed(pixelgl.MouseButtonLeft) {
+			if ctl.JustPressed("grab") || ctl.JustPressed("select") {
 				owner := pg.BelongsTo(x, y)
 				grabbedX = x
 				grabbedY = y
 
-				if win.Pressed(pixelgl.KeyLeftShift) || win.Pressed(pixelgl.KeyLeftShift) {
-					fmt.Println("shifty")
-					pg.Highlighted = append(pg.Highlighted, owner)
-				} else {
-					if owner == clickOwner && time.Now().Sub(clickTime) < time.Duration(350*time.Millisecond) {
-						fmt.Println("doubleclick")
-						if owner.Kind == page.BLUE {
-							newb := owner.Copy()
-							pg.Place(owner.Parent, newb)
-							pg.Grab(newb, x, y)
-						}
-					} else {
-						clickTime = time.Now()
-						clickOwner = owner
-						pg.Grab(owner, x, y)
+				switch {
+				case owner == pg.Root && ctl.JustPressed("select"):
+					// dragging out a box adds everything inside it to the selection
+					shape = newSelectionShape(false, win.MousePosition())
+				case owner == pg.Root:
+					// dragging the background pans instead of moving everything
+					pg.Highlighted = nil
+					panning = true
+				case ctl.JustPressed("select"):
+					pg.Toggle(owner)
+				case owner == clickOwner && ctl.JustPressed("copy"):
+					page.Log.Info("copy", page.Field("bubble", owner))
+					if owner.Kind == page.BLUE {
+						newb := owner.Copy()
+						pg.Place(owner.Parent, newb)
+						pg.Grab(newb, x, y)
 					}
+				default:
+					clickOwner = owner
+					pg.Grab(owner, x, y)
 				}
 			}
+			// Show where the grabbed bubble would go, and whether it's allowed to
+			if pg.Grabbed != nil {
+				drawDropPreview(win, pg, x, y)
+			}
 			// Place grabbed bubble to new location, if possible
-			if win.JustReleased(pixelgl.MouseButtonLeft) {
+			if ctl.JustReleased("grab") {
 				owner := pg.NearestAlternative(x, y)
 				// if this is logically allowed, then do the required operations
-				if pg.CanPlaceAt(owner) {
-					fmt.Println("can place")
-					pg.Execute(func() { pg.ReleaseInto(owner) })
+				if d, err := pg.DropDestination(owner); err == nil {
+					pg.Execute(func() { pg.ApplyMove(d) })
 				} else {
 					// otherwise, just give it back to its original parent
-					fmt.Println("not allowed")
+					status.Set(err)
 					pg.Execute(func() { pg.ReleaseInto(pg.GrabbedParent) })
 				}
 				pg.Grabbed = nil
 			}
 
-			if str := win.Typed(); strings.TrimSpace(str) != "" || win.JustPressed(pixelgl.KeySpace) {
-				switch str {
-				case "!":
-					if len(pg.Highlighted) > 0 && pg.Grabbed == nil {
-						subject := pg.Highlighted[0]
-						if pg.AssumptionPair == nil || (subject != pg.AssumptionPair.Positive && subject != pg.AssumptionPair.Negative) {
-							loopKind := page.BLUE
-							for _, highlighted := range pg.Highlighted {
-								if highlighted.Kind != page.BLUE && !(highlighted.Variable == "" &&
-									len(highlighted.Children) == 0 && highlighted.Kind == page.WHITE) {
-									continue
-								}
-							}
-							pg.Execute(func() { pg.Loop(loopKind, pg.Highlighted...) })
-						}
+			if str := win.Typed(); strings.TrimSpace(str) != "" || ctl.JustPressed("unit") ||
+				ctl.JustPressed("of-course") || ctl.JustPressed("why-not") {
+				switch {
+				case ctl.JustPressed("of-course"):
+					if err := pg.CheckOfCourse(pg.Highlighted...); err != nil {
+						status.Set(err)
+					} else if pg.Grabbed == nil {
+						pg.Execute(func() { pg.Loop(page.BLUE, pg.Highlighted...) })
 					}
-				case "?":
-					if len(pg.Highlighted) > 0 && pg.Grabbed == nil {
+				case ctl.JustPressed("why-not"):
+					if err := pg.CheckLoop(pg.Highlighted...); err != nil {
+						status.Set(err)
+					} else if pg.Grabbed == nil {
 						subject := pg.Highlighted[0]
-						if pg.AssumptionPair == nil || (subject != pg.AssumptionPair.Positive && subject != pg.AssumptionPair.Negative) {
-							loopKind := page.RED
-							for _, highlighted := range pg.Highlighted {
-								if highlighted.Kind != page.BLUE && !(highlighted.Variable == "" &&
-									len(highlighted.Children) == 0 && highlighted.Kind == page.WHITE) {
-									continue
-								}
-							}
-							pg.Execute(func() { pg.Loop(loopKind, pg.Highlighted...) })
-							if len(pg.Highlighted) == 1 && pg.Highlighted[0].Variable == "" {
-								// TODO: Enter contingency mode
+						loopKind := page.RED
+						for _, highlighted := range pg.Highlighted {
+							if highlighted.Kind != page.BLUE && !(highlighted.Variable == "" &&
+								len(highlighted.Children) == 0 && highlighted.Kind == page.WHITE) {
+								continue
 							}
 						}
+						unit := len(pg.Highlighted) == 1 && subject.Variable == "" && len(subject.Children) == 0
+						pg.Execute(func() { pg.Loop(loopKind, pg.Highlighted...) })
+						if unit && pg.Mode == page.ProofMode {
+							// a red loop around a unit can be filled with anything
+							pg.EnterContingencyMode(pg.Highlighted[0])
+						}
 					}
 				default:
 					str = strings.TrimSpace(str)
 
-					if pg.AssumptionMode && len(pg.Highlighted) == 1 || strings.TrimSpace(str) == "" {
+					if pg.Mode == page.AssumptionMode && len(pg.Highlighted) == 1 || strings.TrimSpace(str) == "" {
 						subject := pg.Highlighted[0]
 						if subject.Variable == "" {
 							pg.Execute(func() {
@@ -442,9 +660,9 @@ func run() {
 				}
 			}
 
-			if win.JustPressed(pixelgl.MouseButtonRight) {
+			if ctl.JustPressed("assume") {
 				owner := pg.BelongsTo(x, y)
-				if !pg.AssumptionMode && pg.AssumptionPair == nil {
+				if pg.Mode != page.AssumptionMode && pg.AssumptionPair == nil {
 					// Right click grabs things from "the void"
 					if owner.Kind == page.BLACK {
 						pg.AssumptionPair = &page.Pair{Negative: owner}
@@ -455,7 +673,7 @@ func run() {
 					}
 				} else if pg.InAssumption(owner) {
 					pg.Execute(func() {
-						if owner.Kind != page.RED && owner.Kind != page.BLUE {
+						if !owner.Kind.IsExponential() {
 							newb := pg.NewBubble(x, y, "", owner.Kind)
 							pg.Grab(newb, newb.X, newb.Y)
 							pg.ReleaseInto(owner)
@@ -466,61 +684,36 @@ func run() {
 				}
 			}
 
-			if win.JustReleased(pixelgl.MouseButtonRight) {
+			if ctl.JustReleased("assume") {
 				owner := pg.BelongsTo(x, y)
-				if !pg.AssumptionMode && pg.AssumptionPair != nil {
-					if owner.Kind == page.BLACK {
-						if pg.AssumptionPair.Positive != nil && pg.AssumptionPair.Positive.Parent == owner {
-							// create new bubbles for assumption pair
-							pg.AssumptionPair.Negative = owner
-							newPositive := pg.NewBubble(pg.GrabbedAtX, pg.GrabbedAtY, "", page.WHITE)
-							pg.AssumptionPair.Positive.Insert(newPositive)
-							pg.AssumptionPair.Positive = newPositive
-							newNegative := pg.NewBubble(x, y, "", page.BLACK)
-							pg.AssumptionPair.Negative.Insert(newNegative)
-							pg.AssumptionPair.Negative = newNegative
-							pg.AssumptionPair.Positive.AssumptionPair = pg.AssumptionPair.Negative
-							pg.AssumptionPair.Negative.AssumptionPair = pg.AssumptionPair.Positive
-							pg.AssumptionMode = true
-						} else {
-							pg.ExitAssumptionMode()
-						}
+				if pg.Mode != page.AssumptionMode && pg.AssumptionPair != nil {
+					var err error
+					switch {
+					case owner.Kind == page.BLACK && pg.AssumptionPair.Positive != nil:
+						err = pg.Assume(pg.AssumptionPair.Positive, owner, pg.GrabbedAtX, pg.GrabbedAtY, x, y)
+					case owner.Kind == page.WHITE && pg.AssumptionPair.Negative != nil:
+						err = pg.Assume(owner, pg.AssumptionPair.Negative, x, y, pg.GrabbedAtX, pg.GrabbedAtY)
 					}
-					if owner.Kind == page.WHITE {
-						if pg.AssumptionPair.Negative != nil && pg.AssumptionPair.Negative == owner.Parent {
-							// create new bubbles for assumption pair
-							pg.AssumptionPair.Positive = owner
-							newPositive := pg.NewBubble(x, y, "", page.WHITE)
-							pg.AssumptionPair.Positive.Insert(newPositive)
-							pg.AssumptionPair.Positive = newPositive
-							newNegative := pg.NewBubble(pg.GrabbedAtX, pg.GrabbedAtY, "", page.BLACK)
-							pg.AssumptionPair.Negative.Insert(newNegative)
-							pg.AssumptionPair.Negative = newNegative
-							pg.AssumptionPair.Positive.AssumptionPair = pg.AssumptionPair.Negative
-							pg.AssumptionPair.Negative.AssumptionPair = pg.AssumptionPair.Positive
-							pg.AssumptionMode = true
-						} else {
-							pg.ExitAssumptionMode()
-						}
+					if err != nil || pg.Mode != page.AssumptionMode {
+						status.Set(err)
+						pg.ExitAssumptionMode()
 					}
 				}
 				pg.Grabbed = nil
 				pg.Highlighted = nil
 			}
 
-			if win.JustPressed(pixelgl.KeyTab) {
+			if ctl.JustPressed("loop") {
 				// insert a loop around highlighted bubbles
-				if len(pg.Highlighted) > 0 && pg.Grabbed == nil {
+				if err := pg.CheckLoop(pg.Highlighted...); err != nil {
+					status.Set(err)
+				} else if pg.Grabbed == nil {
 					subject := pg.Highlighted[0]
-					if pg.AssumptionPair == nil || (subject != pg.AssumptionPair.Positive && subject != pg.AssumptionPair.Negative) {
-						loopKind := subject.Parent.OppositePolarity()
-						if len(pg.Highlighted) == 1 {
-							loopKind = subject.OppositePolarity()
-						} else if subject.Parent == pg.Root {
-							continue
-						}
-						pg.Execute(func() { pg.Loop(loopKind, pg.Highlighted...) })
+					loopKind := subject.Parent.Kind.Polarity().Dual()
+					if len(pg.Highlighted) == 1 {
+						loopKind = subject.Kind.Polarity().Dual()
 					}
+					pg.Execute(func() { pg.Loop(loopKind, pg.Highlighted...) })
 				}
 			}
 		}
@@ -528,5 +721,12 @@ func run() {
 }
 
 func main() {
+	flag.Parse()
+	closeLog, err := setupLog()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	defer closeLog()
 	pixelgl.Run(run)
 }